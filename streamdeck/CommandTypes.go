@@ -1,8 +1,8 @@
 package streamdeck
 
 import (
+	"context"
 	"fmt"
-	"time"
 )
 
 type StreamDeckEvent interface {
@@ -11,7 +11,15 @@ type StreamDeckEvent interface {
 }
 
 type ActionAssociatedEvent struct {
-	Event   string `json:"event"`
+	Event string `json:"event"`
+	ActionRef
+}
+
+// ActionRef addresses an action instance. Every command that targets an instance is a
+// method of ActionRef, so they can be called on any action event, on an Instance, or on a
+// ref obtained with GetActionRef from code running outside a handler. Commands are safe
+// to send from any goroutine.
+type ActionRef struct {
 	Action  string `json:"action"`
 	Context string `json:"context"`
 	Device  string `json:"device,omitempty"`
@@ -51,66 +59,55 @@ func (e *ActionAssociatedEvent) GetAction() (string, bool) {
 }
 
 // Gets the global settings associated with the plugin. Causes DidReceiveGlobalSettings to be emitted.
+// Without a deadline on ctx, DefaultRequestTimeout applies.
 //
 // Usage:
 //
-//	globalSettings, err := e.GetGlobalSettings()
+//	globalSettings, err := e.GetGlobalSettings(context.Background())
+//	if errors.Is(err, streamdeck.ErrTimeout) {
+//		...
+//	}
 //
 // Docs: https://docs.elgato.com/sdk/plugins/events-sent#getglobalsettings
-func (e *ActionAssociatedEvent) GetGlobalSettings() (GlobalSettings, error) {
-	ch := registerResponseChannel(PluginConfig.PluginUUID)
-	defer unregisterResponseChannel(PluginConfig.PluginUUID)
-
+func (r *ActionRef) GetGlobalSettings(ctx context.Context) (GlobalSettings, error) {
 	response := GetGlobalSettingsCommand{
 		Event:   "getGlobalSettings",
 		Context: PluginConfig.PluginUUID,
 	}
-	err := SendEventToStreamDeck(response)
+	event, err := request(ctx, PluginConfig.PluginUUID, response)
 	if err != nil {
 		return nil, err
 	}
-
-	select {
-	case event := <-ch:
-		if settingsEvent, ok := event.(*DidReceiveGlobalSettingsEvent); ok {
-			return settingsEvent.Payload.Settings, nil
-		}
-		return nil, fmt.Errorf("unexpected response type")
-	case <-time.After(5 * time.Second):
-		return nil, fmt.Errorf("timeout waiting for global settings")
+	if settingsEvent, ok := event.(*DidReceiveGlobalSettingsEvent); ok {
+		return settingsEvent.Payload.Settings, nil
 	}
+	return nil, fmt.Errorf("unexpected response type")
 }
 
 // Gets the settings associated with an instance of an action. Causes DidReceiveSettings to be emitted.
+// Without a deadline on ctx, DefaultRequestTimeout applies.
 //
 // Usage:
 //
-//	settings, err := e.GetSettings()
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+//	defer cancel()
+//	settings, err := e.GetSettings(ctx)
 //
 // Docs:
 // https://docs.elgato.com/sdk/plugins/events-sent#getsettings
-func (e *ActionAssociatedEvent) GetSettings() (ActionSettings, error) {
-	ch := registerResponseChannel(e.Context)
-	defer unregisterResponseChannel(e.Context)
-
+func (r *ActionRef) GetSettings(ctx context.Context) (ActionSettings, error) {
 	response := GetSettingsCommand{
 		Event:   "getSettings",
-		Context: e.Context,
+		Context: r.Context,
 	}
-	err := SendEventToStreamDeck(response)
+	event, err := request(ctx, r.Context, response)
 	if err != nil {
 		return nil, err
 	}
-
-	select {
-	case event := <-ch:
-		if settingsEvent, ok := event.(*DidReceiveSettingsEvent); ok {
-			return settingsEvent.Payload.Settings, nil
-		}
-		return nil, fmt.Errorf("unexpected response type")
-	case <-time.After(5 * time.Second):
-		return nil, fmt.Errorf("timeout waiting for settings")
+	if settingsEvent, ok := event.(*DidReceiveSettingsEvent); ok {
+		return settingsEvent.Payload.Settings, nil
 	}
+	return nil, fmt.Errorf("unexpected response type")
 }
 
 // Logs a message to the file-system.
@@ -121,16 +118,8 @@ func (e *ActionAssociatedEvent) GetSettings() (ActionSettings, error) {
 //
 // Docs:
 // https://docs.elgato.com/sdk/plugins/events-sent#logmessage
-func (e *ActionAssociatedEvent) LogMessage(message string) error {
-	response := LogMessageCommand{
-		Event: "logMessage",
-		Payload: struct {
-			Message string "json:\"message\""
-		}{
-			Message: message,
-		},
-	}
-	return SendEventToStreamDeck(response)
+func (r *ActionRef) LogMessage(message string) error {
+	return Plugin.LogMessage(message)
 }
 
 // Opens the URL in the user's default browser.
@@ -141,16 +130,8 @@ func (e *ActionAssociatedEvent) LogMessage(message string) error {
 //
 // Docs:
 // https://docs.elgato.com/sdk/plugins/events-sent#openurl
-func (e *ActionAssociatedEvent) OpenUrl(url string) error {
-	response := OpenUrlCommand{
-		Event: "openUrl",
-		Payload: struct {
-			Url string "json:\"url\""
-		}{
-			Url: url,
-		},
-	}
-	return SendEventToStreamDeck(response)
+func (r *ActionRef) OpenUrl(url string) error {
+	return Plugin.OpenUrl(url)
 }
 
 // Sends a message to the property inspector.
@@ -163,34 +144,58 @@ func (e *ActionAssociatedEvent) OpenUrl(url string) error {
 //
 // Docs:
 // https://docs.elgato.com/sdk/plugins/events-sent#sendtopropertyinspector
-func (e *ActionAssociatedEvent) SendToPropertyInspector(payload map[string]any) error {
+func (r *ActionRef) SendToPropertyInspector(payload map[string]any) error {
 	response := SendToPropertyInspectorFromPluginCommand{
 		Event:   "sendToPropertyInspector",
-		Context: e.Context,
+		Context: r.Context,
 		Payload: payload,
 	}
-	return SendEventToStreamDeck(response)
+	return sendToContext(r.Context, response)
 }
 
-// Set's the feedback of an existing layout associated with an action instance.
+// Sets the feedback of an existing layout associated with an action instance. Keys are
+// the item keys of the layout, values are either a property map or a shorthand value.
 //
 // Usage:
 //
-// something
+//	e.SetFeedback(map[string]any{
+//		"title":     "Volume",
+//		"indicator": 42,
+//	})
 //
 // Docs:
 // https://docs.elgato.com/streamdeck/sdk/references/websocket/plugin/#setfeedback
-// !! SetFeedback
+func (r *ActionRef) SetFeedback(payload map[string]any) error {
+	response := SetFeedbackEvent{
+		Event:   "setFeedback",
+		Context: r.Context,
+		Payload: payload,
+	}
+	return sendToContext(r.Context, response)
+}
 
-// Sets the layout associated with an action instance.
+// Sets the layout associated with an action instance. The layout is either a built-in
+// layout ID, like "$B1", or the path of a layout file relative to the plugin bundle.
 //
 // Usage:
 //
-// something
+//	e.SetFeedbackLayout("$B1")
+//	e.SetFeedbackLayout(VolumeLayout.Path)
 //
 // Docs:
 // https://docs.elgato.com/streamdeck/sdk/references/websocket/plugin/#setfeedbacklayout
-// !! SetFeedbackLayout
+func (r *ActionRef) SetFeedbackLayout(layout string) error {
+	response := SetFeedbackLayoutEvent{
+		Event:   "setFeedbackLayout",
+		Context: r.Context,
+		Payload: struct {
+			Layout string "json:\"layout\""
+		}{
+			Layout: layout,
+		},
+	}
+	return sendToContext(r.Context, response)
+}
 
 // Update settings associated with action
 // The plugin and Property Inspector can save persistent data globally. The data will be saved securely
@@ -209,24 +214,22 @@ func (e *ActionAssociatedEvent) SendToPropertyInspector(payload map[string]any)
 //
 // Docs:
 // https://docs.elgato.com/sdk/plugins/events-sent#setglobalsettings
-func (e *ActionAssociatedEvent) SetGlobalSettings(settings map[string]any) error {
-	response := SetGlobalSettingsCommand{
-		Event:   "setGlobalSettings",
-		Context: PluginConfig.PluginUUID,
-		Payload: settings,
-	}
-	return SendEventToStreamDeck(response)
+func (r *ActionRef) SetGlobalSettings(settings map[string]any) error {
+	return Plugin.SetGlobalSettings(settings)
 }
 
-// Sets the image associated with an action instance.
+// Sets the image associated with an action instance. The image is a base64 data URL, an
+// SVG data URL or the path of an image in the plugin bundle; an empty image restores the
+// one from the manifest. Other values are rejected with ErrInvalidCommand.
 //
 // Usage:
 //
-//	e.SetImage("Button was clicked!")
+//	e.SetImage("data:image/png;base64," + base64.StdEncoding.EncodeToString(png))
+//	e.SetImage("imgs/actions/muted")
 //
 // Docs:
 // https://docs.elgato.com/sdk/plugins/events-sent#setimage
-func (e *ActionAssociatedEvent) SetImage(base64image string, options ...uint8) error {
+func (r *ActionRef) SetImage(base64image string, options ...uint8) error {
 	var target, state uint8 = 0, 0
 
 	if len(options) > 0 {
@@ -235,10 +238,16 @@ func (e *ActionAssociatedEvent) SetImage(base64image string, options ...uint8) e
 	if len(options) > 1 {
 		state = options[1]
 	}
+	if err := validateTargetAndState(target, state); err != nil {
+		return err
+	}
+	if err := validateImage(base64image); err != nil {
+		return err
+	}
 
 	response := SetImageCommand{
 		Event:   "setImage",
-		Context: e.Context,
+		Context: r.Context,
 		Payload: struct {
 			Image  string "json:\"image\""
 			Target uint8  "json:\"target\""
@@ -249,7 +258,7 @@ func (e *ActionAssociatedEvent) SetImage(base64image string, options ...uint8) e
 			State:  state,
 		},
 	}
-	return SendEventToStreamDeck(response)
+	return sendToContext(r.Context, response)
 }
 
 // Sets the settings associated with an instance of an action.
@@ -265,13 +274,19 @@ func (e *ActionAssociatedEvent) SetImage(base64image string, options ...uint8) e
 //
 // Docs:
 // https://docs.elgato.com/sdk/plugins/events-sent#setsettings
-func (e *ActionAssociatedEvent) SetSettings(settings map[string]any) error {
+func (r *ActionRef) SetSettings(settings map[string]any) error {
+	settings = stampSettingsVersion(r.Action, settings)
 	response := SetSettingsCommand{
 		Event:   "setSettings",
-		Context: e.Context,
+		Context: r.Context,
 		Payload: settings,
 	}
-	return SendEventToStreamDeck(response)
+	// Settings outlive the instance being visible, so they can still be saved after it disappeared.
+	if err := SendEventToStreamDeck(response); err != nil {
+		return err
+	}
+	updateInstanceSettings(r.Context, settings)
+	return nil
 }
 
 // Sets the current state of an action instance.
@@ -282,17 +297,20 @@ func (e *ActionAssociatedEvent) SetSettings(settings map[string]any) error {
 //
 // Docs:
 // https://docs.elgato.com/sdk/plugins/events-sent#setstate
-func (e *ActionAssociatedEvent) SetState(state uint8) error {
+func (r *ActionRef) SetState(state uint8) error {
+	if state >= MaxStates {
+		return invalidCommand("state %d is out of range 0-%d", state, MaxStates-1)
+	}
 	response := SetStateCommand{
 		Event:   "setState",
-		Context: e.Context,
+		Context: r.Context,
 		Payload: struct {
 			State uint8 "json:\"state\""
 		}{
 			State: state,
 		},
 	}
-	return SendEventToStreamDeck(response)
+	return sendToContext(r.Context, response)
 }
 
 // Sets the title displayed for an instance of an action.
@@ -310,7 +328,7 @@ func (e *ActionAssociatedEvent) SetState(state uint8) error {
 //
 // Docs:
 // https://docs.elgato.com/sdk/plugins/events-sent#settitle
-func (e *ActionAssociatedEvent) SetTitle(title string, options ...uint8) error {
+func (r *ActionRef) SetTitle(title string, options ...uint8) error {
 	var target, state uint8 = 0, 0
 
 	if len(options) > 0 {
@@ -319,10 +337,13 @@ func (e *ActionAssociatedEvent) SetTitle(title string, options ...uint8) error {
 	if len(options) > 1 {
 		state = options[1]
 	}
+	if err := validateTargetAndState(target, state); err != nil {
+		return err
+	}
 
 	response := SetTitleCommand{
 		Event:   "setTitle",
-		Context: e.Context,
+		Context: r.Context,
 		Payload: struct {
 			Title  string "json:\"title\""
 			Target uint8  "json:\"target\""
@@ -333,7 +354,7 @@ func (e *ActionAssociatedEvent) SetTitle(title string, options ...uint8) error {
 			State:  state,
 		},
 	}
-	return SendEventToStreamDeck(response)
+	return sendToContext(r.Context, response)
 }
 
 // !! SetTriggerDescription
@@ -348,12 +369,12 @@ func (e *ActionAssociatedEvent) SetTitle(title string, options ...uint8) error {
 //
 // Docs:
 // https://docs.elgato.com/sdk/plugins/events-sent#showalert
-func (e *ActionAssociatedEvent) ShowAlert() error {
+func (r *ActionRef) ShowAlert() error {
 	response := ShowAlertCommand{
 		Event:   "showAlert",
-		Context: e.Context,
+		Context: r.Context,
 	}
-	return SendEventToStreamDeck(response)
+	return sendToContext(r.Context, response)
 }
 
 // Temporarily shows an "OK" (i.e. success), in the form of a check-mark in a
@@ -366,15 +387,15 @@ func (e *ActionAssociatedEvent) ShowAlert() error {
 //
 // Docs:
 // https://docs.elgato.com/sdk/plugins/events-sent#showok
-func (e *ActionAssociatedEvent) ShowOk() error {
+func (r *ActionRef) ShowOk() error {
 	response := ShowOkCommand{
 		Event:   "showOk",
-		Context: e.Context,
+		Context: r.Context,
 	}
-	return SendEventToStreamDeck(response)
+	return sendToContext(r.Context, response)
 }
 
-// Switches to the profile, as distributed by the plugin, on the specified device.
+// Switches to the profile, as distributed by the plugin, on the device of the action instance.
 //
 // NB: Plugins may only switch to profiles distributed with the plugin, as defined
 // within the manifest, and cannot access user-defined profiles.
@@ -386,25 +407,8 @@ func (e *ActionAssociatedEvent) ShowOk() error {
 //
 // Docs:
 // https://docs.elgato.com/sdk/plugins/events-sent#switchtoprofile
-func (e *ActionAssociatedEvent) SwitchToProfile(profile string, page ...uint8) error {
-	var pageIndex uint8 = 0
-
-	if len(page) > 0 {
-		pageIndex = page[0]
-	}
-	response := SwitchToProfileCommand{
-		Event:   "switchToProfile",
-		Context: PluginConfig.PluginUUID,
-		Device:  e.Device,
-		Payload: struct {
-			Profile string "json:\"profile\""
-			Page    uint8  "json:\"page\""
-		}{
-			Profile: profile,
-			Page:    pageIndex,
-		},
-	}
-	return SendEventToStreamDeck(response)
+func (r *ActionRef) SwitchToProfile(profile string, page ...uint8) error {
+	return Plugin.SwitchToProfile(r.Device, profile, page...)
 }
 
 type ActionCoordinates struct {
@@ -425,6 +429,8 @@ type DidReceiveSettingsEvent struct {
 		Coordinates     ActionCoordinates `json:"coordinates"`
 		IsInMultiAction bool              `json:"isInMultiAction"`
 	} `json:"payload"`
+
+	previous ActionSettings
 }
 
 type DidReceiveGlobalSettingsEvent struct {
@@ -434,6 +440,13 @@ type DidReceiveGlobalSettingsEvent struct {
 	} `json:"payload"`
 }
 
+type DidReceiveSecretsEvent struct {
+	GlobalEvent
+	Payload struct {
+		Secrets map[string]string `json:"secrets"`
+	} `json:"payload"`
+}
+
 type DidReceiveDeepLinkEvent struct {
 	GlobalEvent
 	Payload struct {
@@ -486,7 +499,7 @@ type KeyDownEvent struct {
 	Payload struct {
 		Settings         ActionSettings    `json:"settings"`
 		Coordinates      ActionCoordinates `json:"coordinates"`
-		State            int               `json:"states"`
+		State            int               `json:"state"`
 		UserDesiredState int               `json:"userDesiredState"`
 		IsInMultiAction  bool              `json:"isInMultiAction"`
 	} `json:"payload"`
@@ -547,12 +560,9 @@ type TitleParametersDidChangeEvent struct {
 type DeviceDidConnectEvent struct {
 	GlobalEvent
 	DeviceInfo struct {
-		Name string `json:"name"`
-		Type int    `json:"type"`
-		Size struct {
-			Columns int `json:"columns"`
-			Rows    int `json:"rows"`
-		} `json:"size"`
+		Name string     `json:"name"`
+		Type DeviceType `json:"type"`
+		Size DeviceSize `json:"size"`
 	} `json:"deviceInfo"`
 }
 
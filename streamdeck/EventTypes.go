@@ -22,6 +22,11 @@ type GetGlobalSettingsCommand struct {
 	Context string `json:"context"`
 }
 
+type GetSecretsCommand struct {
+	Event   string `json:"event"`
+	Context string `json:"context"`
+}
+
 type OpenUrlCommand struct {
 	Event   string `json:"event"`
 	Payload struct {
@@ -63,10 +68,12 @@ type SetFeedbackEvent struct {
 } //not really sure about this one
 
 type SetFeedbackLayoutEvent struct {
-	Event   string            `json:"event"`
-	Context string            `json:"context"`
-	Payload map[string]string `json:"payload"`
-} //not really sure about this one
+	Event   string `json:"event"`
+	Context string `json:"context"`
+	Payload struct {
+		Layout string `json:"layout"`
+	} `json:"payload"`
+}
 
 type SetTriggerDescriptionEvent struct {
 	Event   string `json:"event"`
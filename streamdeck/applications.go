@@ -0,0 +1,144 @@
+package streamdeck
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ApplicationCallback is called with the identifier of an application that launched or
+// terminated.
+type ApplicationCallback func(application string)
+
+type applicationSubscription struct {
+	onLaunch    ApplicationCallback
+	onTerminate ApplicationCallback
+}
+
+var (
+	applicationSubscriptions = make(map[string][]applicationSubscription)
+	runningApplications      = make(map[string]bool)
+	applicationMutex         sync.RWMutex
+)
+
+// Registers callbacks for when an application launches or terminates. Either callback may
+// be nil. Applications are identified by their bundle ID on macOS, and by the name of
+// their executable, ending in .exe, on Windows.
+//
+// The Stream Deck only reports applications listed under ApplicationsToMonitor in the
+// manifest; MonitoredApplications returns them keyed by platform, and WriteManifest puts
+// them there.
+//
+// Usage:
+//
+//	onLaunch := func(app string) {
+//		streamdeck.ForEachInstance(NowPlaying.UUID, func(inst streamdeck.Instance) {
+//			inst.SetState(1)
+//		})
+//	}
+//	streamdeck.OnApplication("com.apple.Music", onLaunch, nil)
+//	streamdeck.OnApplication("iTunes.exe", onLaunch, nil)
+func OnApplication(application string, onLaunch, onTerminate ApplicationCallback) {
+	applicationMutex.Lock()
+	defer applicationMutex.Unlock()
+
+	applicationSubscriptions[application] = append(applicationSubscriptions[application], applicationSubscription{
+		onLaunch:    onLaunch,
+		onTerminate: onTerminate,
+	})
+}
+
+// ApplicationsToMonitor lists the monitored applications for each platform, in the shape
+// of the manifest's ApplicationsToMonitor.
+//
+// Docs:
+// https://docs.elgato.com/streamdeck/sdk/references/manifest#applicationstomonitor
+type ApplicationsToMonitor map[Platform][]string
+
+// applicationPlatform tells which platform an application identifier belongs to.
+func applicationPlatform(application string) Platform {
+	if strings.HasSuffix(strings.ToLower(application), ".exe") {
+		return PlatformWindows
+	}
+	return PlatformMac
+}
+
+// Returns every application registered with OnApplication, sorted and keyed by platform,
+// for the manifest's ApplicationsToMonitor.
+func MonitoredApplications() ApplicationsToMonitor {
+	applicationMutex.RLock()
+	defer applicationMutex.RUnlock()
+
+	applications := make(ApplicationsToMonitor)
+	for application := range applicationSubscriptions {
+		platform := applicationPlatform(application)
+		applications[platform] = append(applications[platform], application)
+	}
+	for _, list := range applications {
+		sort.Strings(list)
+	}
+	return applications
+}
+
+// Reports whether a monitored application is running.
+//
+// Usage:
+//
+//	if streamdeck.IsRunning("com.apple.Music") {
+//		...
+//	}
+func IsRunning(application string) bool {
+	applicationMutex.RLock()
+	defer applicationMutex.RUnlock()
+	return runningApplications[application]
+}
+
+// trackApplication keeps the running applications up to date.
+func trackApplication(event StreamDeckEvent) {
+	applicationMutex.Lock()
+	defer applicationMutex.Unlock()
+
+	switch e := event.(type) {
+	case *ApplicationDidLaunchEvent:
+		runningApplications[e.Payload.Application] = true
+	case *ApplicationDidTerminateEvent:
+		delete(runningApplications, e.Payload.Application)
+	}
+}
+
+// resetRunningApplications forgets which applications are running. Subscriptions are kept.
+func resetRunningApplications() {
+	applicationMutex.Lock()
+	runningApplications = make(map[string]bool)
+	applicationMutex.Unlock()
+}
+
+// notifyApplication calls the callbacks registered for the application in the event.
+func notifyApplication(event StreamDeckEvent) {
+	var (
+		application string
+		launched    bool
+	)
+	switch e := event.(type) {
+	case *ApplicationDidLaunchEvent:
+		application, launched = e.Payload.Application, true
+	case *ApplicationDidTerminateEvent:
+		application = e.Payload.Application
+	default:
+		return
+	}
+
+	applicationMutex.RLock()
+	subscriptions := applicationSubscriptions[application]
+	applicationMutex.RUnlock()
+
+	for _, subscription := range subscriptions {
+		callback := subscription.onTerminate
+		if launched {
+			callback = subscription.onLaunch
+		}
+		if callback != nil {
+			callback(application)
+		}
+	}
+}
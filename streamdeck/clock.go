@@ -0,0 +1,57 @@
+package streamdeck
+
+import (
+	"sync"
+	"time"
+)
+
+// clock schedules the SDK's timers, gestures and dial acceleration. A replay swaps it for
+// one it advances itself, so timers fire at the recorded times instead of the wall clock's.
+type clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) clockTimer
+}
+
+// clockTimer is the part of *time.Timer the SDK uses.
+type clockTimer interface {
+	Stop() bool
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) clockTimer {
+	return time.AfterFunc(d, f)
+}
+
+var (
+	sdkClock   clock = realClock{}
+	clockMutex sync.RWMutex
+)
+
+func currentClock() clock {
+	clockMutex.RLock()
+	defer clockMutex.RUnlock()
+	return sdkClock
+}
+
+// setClock replaces the clock and returns the previous one.
+func setClock(c clock) clock {
+	clockMutex.Lock()
+	defer clockMutex.Unlock()
+
+	previous := sdkClock
+	sdkClock = c
+	return previous
+}
+
+func now() time.Time {
+	return currentClock().Now()
+}
+
+func afterFunc(d time.Duration, f func()) clockTimer {
+	return currentClock().AfterFunc(d, f)
+}
@@ -0,0 +1,181 @@
+package streamdeck
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrContextGone is returned for commands sent to an action instance that has
+	// disappeared.
+	ErrContextGone = errors.New("action instance has disappeared")
+	// ErrInvalidCommand is returned for commands whose payload the Stream Deck would reject.
+	ErrInvalidCommand = errors.New("invalid command")
+)
+
+const (
+	MaxTarget = 2 // Highest target: 0 is both, 1 hardware only, 2 software only.
+	MaxStates = 2 // Number of states an action can declare in its manifest.
+)
+
+// CommandStats counts the commands sent to action instances.
+type CommandStats struct {
+	Sent        uint64 // Written to the WebSocket.
+	Failed      uint64 // Could not be written to the WebSocket.
+	Invalid     uint64 // Rejected before sending because of their payload.
+	ContextGone uint64 // Rejected before sending because the instance had disappeared.
+}
+
+var commandStats struct {
+	sent, failed, invalid, contextGone atomic.Uint64
+}
+
+// Returns how many commands were sent and rejected since the plugin started.
+//
+// Usage:
+//
+//	stats := streamdeck.CommandCounts()
+//	log.Printf("%d commands rejected", stats.Invalid+stats.ContextGone)
+func CommandCounts() CommandStats {
+	return CommandStats{
+		Sent:        commandStats.sent.Load(),
+		Failed:      commandStats.failed.Load(),
+		Invalid:     commandStats.invalid.Load(),
+		ContextGone: commandStats.contextGone.Load(),
+	}
+}
+
+// maxGoneContexts bounds how many disappeared instances are remembered. Contexts of deleted
+// actions never appear again, so the oldest are forgotten first.
+const maxGoneContexts = 1024
+
+var (
+	goneContexts = make(map[string]uint64) // Context to the order it disappeared in.
+	goneOrder    []goneContext
+	goneSeq      uint64
+	goneMutex    sync.RWMutex
+)
+
+type goneContext struct {
+	context string
+	seq     uint64
+}
+
+// trackGoneContexts remembers which instances disappeared, until they appear again.
+func trackGoneContexts(event StreamDeckEvent) {
+	goneMutex.Lock()
+	defer goneMutex.Unlock()
+
+	switch e := event.(type) {
+	case *WillAppearEvent:
+		delete(goneContexts, e.Context)
+	case *WillDisappearEvent:
+		goneSeq++
+		goneContexts[e.Context] = goneSeq
+		goneOrder = append(goneOrder, goneContext{e.Context, goneSeq})
+
+		for len(goneOrder) > maxGoneContexts {
+			oldest := goneOrder[0]
+			goneOrder = goneOrder[1:]
+			// Skip entries for instances that appeared, or disappeared again since.
+			if goneContexts[oldest.context] == oldest.seq {
+				delete(goneContexts, oldest.context)
+			}
+		}
+	}
+}
+
+func resetGoneContexts() {
+	goneMutex.Lock()
+	goneContexts = make(map[string]uint64)
+	goneOrder = nil
+	goneMutex.Unlock()
+}
+
+func checkContext(context string) error {
+	goneMutex.RLock()
+	_, gone := goneContexts[context]
+	goneMutex.RUnlock()
+
+	if gone {
+		return contextGoneError(context)
+	}
+	return nil
+}
+
+// contextGoneError counts a command rejected because its instance isn't visible.
+func contextGoneError(context string) error {
+	commandStats.contextGone.Add(1)
+	return fmt.Errorf("%w: %s", ErrContextGone, context)
+}
+
+// sendToContext sends a command addressed to an action instance.
+func sendToContext(context string, command any) error {
+	if err := checkContext(context); err != nil {
+		return err
+	}
+	if err := SendEventToStreamDeck(command); err != nil {
+		commandStats.failed.Add(1)
+		return err
+	}
+	commandStats.sent.Add(1)
+	return nil
+}
+
+func invalidCommand(format string, args ...any) error {
+	commandStats.invalid.Add(1)
+	return fmt.Errorf("%w: %s", ErrInvalidCommand, fmt.Sprintf(format, args...))
+}
+
+func validateTargetAndState(target, state uint8) error {
+	if target > MaxTarget {
+		return invalidCommand("target %d is out of range 0-%d", target, MaxTarget)
+	}
+	if state >= MaxStates {
+		return invalidCommand("state %d is out of range 0-%d", state, MaxStates-1)
+	}
+	return nil
+}
+
+// validateImage checks an image for setImage: a base64 data URL of an image, an SVG data
+// URL, or the path of an image inside the plugin bundle. Empty resets the image to the one
+// from the manifest.
+func validateImage(image string) error {
+	if image == "" {
+		return nil
+	}
+	if data, ok := strings.CutPrefix(image, "data:"); ok {
+		header, content, ok := strings.Cut(data, ",")
+		if !ok {
+			return invalidCommand("image data URL has no data")
+		}
+		mediaType, params, _ := strings.Cut(header, ";")
+		if !strings.HasPrefix(mediaType, "image/") {
+			return invalidCommand("image data URL has media type %q", mediaType)
+		}
+		if strings.HasSuffix(params, "base64") {
+			if _, err := base64.StdEncoding.DecodeString(content); err != nil {
+				return invalidCommand("image data URL is not valid base64: %v", err)
+			}
+			return nil
+		}
+		if mediaType != "image/svg+xml" {
+			return invalidCommand("%s data URL must be base64 encoded", mediaType)
+		}
+		return nil
+	}
+
+	if strings.Contains(image, "://") || path.IsAbs(filepath.ToSlash(image)) || filepath.IsAbs(image) {
+		return invalidCommand("image %q is neither a data URL nor a path in the plugin bundle", image)
+	}
+	if clean := path.Clean(filepath.ToSlash(image)); clean == ".." || strings.HasPrefix(clean, "../") {
+		return invalidCommand("image path %q is outside the plugin bundle", image)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package streamdeck
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DeepLink is a deep link matched by the DeepLinkRouter.
+type DeepLink struct {
+	URL    *url.URL
+	Path   string            // Path after streamdeck://plugins/message/<uuid>, e.g. "/toggle/3".
+	Params map[string]string // Values of the {placeholders} in the route pattern.
+	Query  url.Values
+}
+
+// Returns the path parameter with the given name, or else the query parameter.
+func (l DeepLink) Param(name string) string {
+	if value, ok := l.Params[name]; ok {
+		return value
+	}
+	return l.Query.Get(name)
+}
+
+// Returns the named parameter as an int.
+//
+// Usage:
+//
+//	id, err := link.Int("id")
+func (l DeepLink) Int(name string) (int, error) {
+	value, err := strconv.Atoi(l.Param(name))
+	if err != nil {
+		return 0, fmt.Errorf("deep link parameter %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// Returns the named parameter as a float64.
+func (l DeepLink) Float(name string) (float64, error) {
+	value, err := strconv.ParseFloat(l.Param(name), 64)
+	if err != nil {
+		return 0, fmt.Errorf("deep link parameter %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// Returns the named parameter as a bool.
+func (l DeepLink) Bool(name string) (bool, error) {
+	value, err := strconv.ParseBool(l.Param(name))
+	if err != nil {
+		return false, fmt.Errorf("deep link parameter %s: %w", name, err)
+	}
+	return value, nil
+}
+
+type DeepLinkHandler func(link DeepLink)
+
+type deepLinkRoute struct {
+	segments []string
+	handler  DeepLinkHandler
+}
+
+// DeepLinkRouter dispatches streamdeck://plugins/message/<uuid>/... deep links to the
+// first handler whose pattern matches the path. Once a route is registered, deep links
+// are no longer broadcast to the actions' HandleDidReceiveDeepLink.
+type DeepLinkRouter struct {
+	mu       sync.RWMutex
+	routes   []deepLinkRoute
+	notFound DeepLinkHandler
+}
+
+// Registers a handler for a path pattern. Segments written as {name} match any value,
+// which is available as link.Params["name"].
+//
+// Usage:
+//
+//	streamdeck.Plugin.DeepLinks().Handle("/toggle/{id}", func(link streamdeck.DeepLink) {
+//		id, err := link.Int("id")
+//		...
+//	})
+func (r *DeepLinkRouter) Handle(pattern string, handler DeepLinkHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, deepLinkRoute{segments: splitDeepLinkPath(pattern), handler: handler})
+}
+
+// Registers a handler for deep links that match none of the routes.
+func (r *DeepLinkRouter) NotFound(handler DeepLinkHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notFound = handler
+}
+
+// Dispatch routes a deep link URL. It reports false when no routes are registered.
+func (r *DeepLinkRouter) Dispatch(rawURL string) bool {
+	r.mu.RLock()
+	routes := r.routes
+	notFound := r.notFound
+	r.mu.RUnlock()
+
+	if len(routes) == 0 && notFound == nil {
+		return false
+	}
+
+	link, err := parseDeepLink(rawURL)
+	if err != nil {
+		log.Printf("Error parsing deep link: %v", err)
+		return true
+	}
+
+	segments := splitDeepLinkPath(link.Path)
+	for _, route := range routes {
+		if params, ok := route.match(segments); ok {
+			link.Params = params
+			route.handler(link)
+			return true
+		}
+	}
+
+	if notFound != nil {
+		notFound(link)
+	} else {
+		log.Printf("No deep link route for %s", link.Path)
+	}
+	return true
+}
+
+func (route deepLinkRoute) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(route.segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, segment := range route.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params[segment[1:len(segment)-1]] = segments[i]
+		} else if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// parseDeepLink strips the streamdeck://plugins/message/<uuid> prefix from a deep link.
+func parseDeepLink(rawURL string) (DeepLink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return DeepLink{}, err
+	}
+
+	path := u.Path
+	if u.Scheme == "streamdeck" {
+		rest, ok := strings.CutPrefix(u.Path, "/message/")
+		if u.Host != "plugins" || !ok {
+			return DeepLink{}, fmt.Errorf("not a plugin deep link: %s", rawURL)
+		}
+		_, path, _ = strings.Cut(rest, "/")
+		path = "/" + path
+	}
+
+	return DeepLink{URL: u, Path: path, Query: u.Query()}, nil
+}
+
+func splitDeepLinkPath(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
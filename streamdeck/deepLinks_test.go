@@ -0,0 +1,120 @@
+package streamdeck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeepLinkRouterDispatch(t *testing.T) {
+	const prefix = "streamdeck://plugins/message/com.example.plugin"
+
+	tests := []struct {
+		name       string
+		url        string
+		wantRoute  string
+		wantParams map[string]string
+		wantPath   string
+	}{
+		{name: "static route", url: prefix + "/refresh", wantRoute: "/refresh", wantParams: map[string]string{}, wantPath: "/refresh"},
+		{name: "placeholder", url: prefix + "/toggle/3", wantRoute: "/toggle/{id}", wantParams: map[string]string{"id": "3"}, wantPath: "/toggle/3"},
+		{
+			name:       "several placeholders",
+			url:        prefix + "/device/abc/key/7",
+			wantRoute:  "/device/{device}/key/{key}",
+			wantParams: map[string]string{"device": "abc", "key": "7"},
+			wantPath:   "/device/abc/key/7",
+		},
+		{name: "trailing slash", url: prefix + "/refresh/", wantRoute: "/refresh", wantParams: map[string]string{}, wantPath: "/refresh/"},
+		{name: "escaped segment", url: prefix + "/toggle/a%20b", wantRoute: "/toggle/{id}", wantParams: map[string]string{"id": "a b"}, wantPath: "/toggle/a b"},
+		{name: "first matching route wins", url: prefix + "/toggle/all", wantRoute: "/toggle/{id}", wantParams: map[string]string{"id": "all"}, wantPath: "/toggle/all"},
+		{name: "too many segments", url: prefix + "/toggle/3/extra", wantRoute: "notFound", wantPath: "/toggle/3/extra"},
+		{name: "no path", url: prefix, wantRoute: "notFound", wantPath: "/"},
+		{name: "plain path", url: "/refresh", wantRoute: "/refresh", wantParams: map[string]string{}, wantPath: "/refresh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				gotRoute string
+				gotLink  DeepLink
+			)
+			router := &DeepLinkRouter{}
+			for _, pattern := range []string{"/refresh", "/toggle/{id}", "/toggle/all", "/device/{device}/key/{key}"} {
+				router.Handle(pattern, func(link DeepLink) {
+					gotRoute, gotLink = pattern, link
+				})
+			}
+			router.NotFound(func(link DeepLink) {
+				gotRoute, gotLink = "notFound", link
+			})
+
+			if !router.Dispatch(tt.url) {
+				t.Fatal("Dispatch reported no routes")
+			}
+			if gotRoute != tt.wantRoute {
+				t.Errorf("got route %s, want %s", gotRoute, tt.wantRoute)
+			}
+			if tt.wantParams != nil && !reflect.DeepEqual(gotLink.Params, tt.wantParams) {
+				t.Errorf("got params %v, want %v", gotLink.Params, tt.wantParams)
+			}
+			if gotLink.Path != tt.wantPath {
+				t.Errorf("got path %q, want %q", gotLink.Path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestDeepLinkRouterWithoutRoutes(t *testing.T) {
+	if (&DeepLinkRouter{}).Dispatch("streamdeck://plugins/message/com.example.plugin/refresh") {
+		t.Error("Dispatch reported handling a link without any routes")
+	}
+}
+
+func TestDeepLinkRouterRejectsOtherLinks(t *testing.T) {
+	called := false
+	router := &DeepLinkRouter{}
+	router.NotFound(func(DeepLink) { called = true })
+
+	for _, url := range []string{
+		"streamdeck://profiles/message/com.example.plugin/refresh",
+		"streamdeck://plugins/other/com.example.plugin/refresh",
+	} {
+		router.Dispatch(url)
+	}
+	if called {
+		t.Error("a link that isn't a plugin deep link reached the router")
+	}
+}
+
+func TestDeepLinkParams(t *testing.T) {
+	link, err := parseDeepLink("streamdeck://plugins/message/com.example.plugin/set/4?volume=0.5&muted=true&id=9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	link.Params = map[string]string{"id": "4"}
+
+	tests := []struct {
+		name string
+		get  func() (any, error)
+		want any
+	}{
+		{name: "path param wins over query", get: func() (any, error) { return link.Int("id") }, want: 4},
+		{name: "float from query", get: func() (any, error) { return link.Float("volume") }, want: 0.5},
+		{name: "bool from query", get: func() (any, error) { return link.Bool("muted") }, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := link.Int("missing"); err == nil {
+		t.Error("got no error for a missing parameter")
+	}
+}
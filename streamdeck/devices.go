@@ -0,0 +1,145 @@
+package streamdeck
+
+import (
+	"sort"
+	"sync"
+)
+
+// DeviceType identifies the kind of hardware a device is.
+//
+// Docs:
+// https://docs.elgato.com/streamdeck/sdk/references/websocket/plugin/#devicedidconnect
+type DeviceType int
+
+const (
+	DeviceStreamDeck DeviceType = iota
+	DeviceStreamDeckMini
+	DeviceStreamDeckXL
+	DeviceStreamDeckMobile
+	DeviceCorsairGKeys
+	DeviceStreamDeckPedal
+	DeviceCorsairVoyager
+	DeviceStreamDeckPlus
+	DeviceSCUFController
+	DeviceStreamDeckNeo
+)
+
+var deviceTypeNames = map[DeviceType]string{
+	DeviceStreamDeck:       "Stream Deck",
+	DeviceStreamDeckMini:   "Stream Deck Mini",
+	DeviceStreamDeckXL:     "Stream Deck XL",
+	DeviceStreamDeckMobile: "Stream Deck Mobile",
+	DeviceCorsairGKeys:     "Corsair G Keys",
+	DeviceStreamDeckPedal:  "Stream Deck Pedal",
+	DeviceCorsairVoyager:   "Corsair Voyager",
+	DeviceStreamDeckPlus:   "Stream Deck +",
+	DeviceSCUFController:   "SCUF Controller",
+	DeviceStreamDeckNeo:    "Stream Deck Neo",
+}
+
+func (t DeviceType) String() string {
+	if name, ok := deviceTypeNames[t]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+type DeviceSize struct {
+	Columns int `json:"columns"`
+	Rows    int `json:"rows"`
+}
+
+// Device is the SDK's view of a piece of hardware known to the Stream Deck application.
+type Device struct {
+	ID        string
+	Name      string
+	Type      DeviceType
+	Size      DeviceSize
+	Connected bool
+}
+
+var (
+	deviceRegistry = make(map[string]*Device)
+	deviceMutex    sync.RWMutex
+)
+
+// Returns every device the Stream Deck application has told the plugin about, ordered by ID.
+// Devices stay in the list after they disconnect, with Connected set to false.
+//
+// Usage:
+//
+//	for _, device := range streamdeck.Devices() {
+//		log.Printf("%s (%s) connected: %t", device.Name, device.Type, device.Connected)
+//	}
+func Devices() []Device {
+	deviceMutex.RLock()
+	defer deviceMutex.RUnlock()
+
+	devices := make([]Device, 0, len(deviceRegistry))
+	for _, device := range deviceRegistry {
+		devices = append(devices, *device)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].ID < devices[j].ID })
+	return devices
+}
+
+// Returns the device with the given ID.
+//
+// Usage:
+//
+//	device, ok := streamdeck.GetDevice(e.Device)
+func GetDevice(id string) (Device, bool) {
+	deviceMutex.RLock()
+	defer deviceMutex.RUnlock()
+
+	device, ok := deviceRegistry[id]
+	if !ok {
+		return Device{}, false
+	}
+	return *device, true
+}
+
+// seedDevices fills the registry from the devices listed in the -info argument,
+// all of which are connected when the plugin starts.
+func seedDevices(info Info) {
+	deviceMutex.Lock()
+	defer deviceMutex.Unlock()
+
+	for _, d := range info.Devices {
+		deviceRegistry[d.Id] = &Device{
+			ID:        d.Id,
+			Name:      d.Name,
+			Type:      d.Type,
+			Size:      d.Size,
+			Connected: true,
+		}
+	}
+}
+
+// trackDevice keeps the registry in step with deviceDidConnect and deviceDidDisconnect.
+func trackDevice(event StreamDeckEvent) {
+	deviceMutex.Lock()
+	defer deviceMutex.Unlock()
+
+	switch e := event.(type) {
+	case *DeviceDidConnectEvent:
+		deviceRegistry[e.Device] = &Device{
+			ID:        e.Device,
+			Name:      e.DeviceInfo.Name,
+			Type:      e.DeviceInfo.Type,
+			Size:      e.DeviceInfo.Size,
+			Connected: true,
+		}
+	case *DeviceDidDisconnectEvent:
+		// A device the plugin never heard of has no known type, so it isn't added.
+		if device, ok := deviceRegistry[e.Device]; ok {
+			device.Connected = false
+		}
+	}
+}
+
+func resetDevices() {
+	deviceMutex.Lock()
+	deviceRegistry = make(map[string]*Device)
+	deviceMutex.Unlock()
+}
@@ -0,0 +1,198 @@
+package streamdeck
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+// DialValueOptions configures a DialValue. Zero values fall back to the defaults below.
+type DialValueOptions struct {
+	Min     float64
+	Max     float64 // Must be greater than Min.
+	Step    float64 // Change per tick. Defaults to 1.
+	Default float64 // Used when the settings don't hold a value yet.
+
+	FineStep float64 // Change per tick while the dial is pressed. Defaults to Step / 10.
+
+	Acceleration       float64       // Maximum step multiplier for fast rotations. 0 or 1 disables acceleration.
+	AccelerationWindow time.Duration // Rotations closer together than this accelerate. Defaults to 150ms.
+
+	SettingsKey string // Settings key the value is persisted under. Empty disables persistence.
+	FeedbackKey string // Bar item refreshed via setFeedback. Defaults to "indicator", "-" disables it.
+}
+
+// DialValue is a numeric value bound to a dial. It applies rotations with clamping,
+// acceleration and a fine mode while pressed, persists the value to the instance's
+// settings once the dial comes to rest and keeps the touch strip bar up to date.
+type DialValue struct {
+	mu         sync.Mutex
+	context    string
+	opts       DialValueOptions
+	value      float64
+	lastRotate time.Time
+
+	saveTimer clockTimer
+	settings  ActionSettings // Settings of the instance when the value last changed.
+}
+
+const (
+	defaultAccelerationWindow = 150 * time.Millisecond
+
+	// dialSaveDelay is how long a DialValue waits after the last change before saving it,
+	// so turning the dial doesn't send setSettings for every tick.
+	dialSaveDelay = 250 * time.Millisecond
+)
+
+// Binds a DialValue to the instance. The initial value is read from the instance's
+// settings when SettingsKey is set.
+//
+// Usage:
+//
+//	volume, err := inst.BindDialValue(streamdeck.DialValueOptions{
+//		Min: 0, Max: 100, Step: 2,
+//		Acceleration: 5,
+//		SettingsKey:  "volume",
+//	})
+func (i *Instance) BindDialValue(opts DialValueOptions) (*DialValue, error) {
+	if opts.Max <= opts.Min {
+		return nil, fmt.Errorf("dial value max must be greater than min")
+	}
+	if opts.Step <= 0 {
+		opts.Step = 1
+	}
+	if opts.FineStep <= 0 {
+		opts.FineStep = opts.Step / 10
+	}
+	if opts.AccelerationWindow <= 0 {
+		opts.AccelerationWindow = defaultAccelerationWindow
+	}
+	if opts.FeedbackKey == "" {
+		opts.FeedbackKey = "indicator"
+	}
+
+	d := &DialValue{context: i.Context, opts: opts, value: opts.Default}
+	if opts.SettingsKey != "" {
+		if stored, ok := i.Settings[opts.SettingsKey].(float64); ok {
+			d.value = stored
+		}
+	}
+	d.value = d.clamp(d.value)
+	return d, nil
+}
+
+// Returns the current value.
+func (d *DialValue) Value() float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.value
+}
+
+// Applies a dial rotation and returns the new value.
+//
+// Usage:
+//
+//	func (a *VolumeAction) HandleDialRotate(event *streamdeck.DialRotateEvent) {
+//		value, _ := a.volumes[event.Context].Apply(event)
+//		setSystemVolume(value)
+//	}
+func (d *DialValue) Apply(event *DialRotateEvent) (float64, error) {
+	d.mu.Lock()
+	step := d.opts.Step
+	if event.Payload.Pressed {
+		step = d.opts.FineStep
+	} else {
+		step *= d.acceleration(now())
+	}
+	d.value = d.clamp(d.value + float64(event.Payload.Ticks)*step)
+	value := d.value
+	d.mu.Unlock()
+
+	return value, d.publish(value)
+}
+
+// Sets the value directly, clamped to the configured range.
+//
+// Usage:
+//
+//	volume.Set(50)
+func (d *DialValue) Set(value float64) error {
+	d.mu.Lock()
+	d.value = d.clamp(value)
+	value = d.value
+	d.mu.Unlock()
+
+	return d.publish(value)
+}
+
+// acceleration must be called with d.mu held.
+func (d *DialValue) acceleration(now time.Time) float64 {
+	since := now.Sub(d.lastRotate)
+	d.lastRotate = now
+	if d.opts.Acceleration <= 1 || since >= d.opts.AccelerationWindow {
+		return 1
+	}
+	speed := 1 - float64(since)/float64(d.opts.AccelerationWindow)
+	return 1 + (d.opts.Acceleration-1)*speed
+}
+
+func (d *DialValue) clamp(value float64) float64 {
+	return math.Max(d.opts.Min, math.Min(d.opts.Max, value))
+}
+
+// publish refreshes the bar and schedules saving the value.
+func (d *DialValue) publish(value float64) error {
+	inst, ok := GetInstance(d.context)
+	if !ok {
+		return contextGoneError(d.context)
+	}
+
+	if d.opts.SettingsKey != "" {
+		d.scheduleSave(inst)
+	}
+
+	if d.opts.FeedbackKey != "-" {
+		percent := (value - d.opts.Min) / (d.opts.Max - d.opts.Min) * 100
+		return inst.SetFeedback(map[string]any{
+			d.opts.FeedbackKey: int(math.Round(percent)),
+		})
+	}
+	return nil
+}
+
+// scheduleSave saves the value after dialSaveDelay, unless it changes again before then.
+func (d *DialValue) scheduleSave(inst Instance) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.settings = inst.Settings
+	if d.saveTimer != nil {
+		d.saveTimer.Stop()
+	}
+	ref := inst.ActionRef
+	d.saveTimer = afterFunc(dialSaveDelay, func() { d.save(ref) })
+}
+
+// save writes the current value into the instance's settings. Settings outlive the
+// instance being visible, so the value is saved even when it disappeared in the meantime.
+func (d *DialValue) save(ref ActionRef) {
+	d.mu.Lock()
+	value := d.value
+	base := d.settings
+	d.mu.Unlock()
+
+	if inst, ok := GetInstance(ref.Context); ok {
+		base = inst.Settings
+	}
+	settings := make(ActionSettings, len(base)+1)
+	for key, v := range base {
+		settings[key] = v
+	}
+	settings[d.opts.SettingsKey] = value
+
+	if err := ref.SetSettings(settings); err != nil {
+		log.Printf("Error saving dial value of %s: %v", ref.Context, err)
+	}
+}
@@ -0,0 +1,87 @@
+package streamdeck
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBindDialValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     DialValueOptions
+		settings ActionSettings
+		want     float64
+		wantErr  bool
+	}{
+		{name: "default", opts: DialValueOptions{Min: 0, Max: 100, Default: 40}, want: 40},
+		{name: "stored value", opts: DialValueOptions{Min: 0, Max: 100, Default: 40, SettingsKey: "volume"}, settings: ActionSettings{"volume": 75.0}, want: 75},
+		{name: "stored value is clamped", opts: DialValueOptions{Min: 0, Max: 100, SettingsKey: "volume"}, settings: ActionSettings{"volume": 150.0}, want: 100},
+		{name: "default is clamped", opts: DialValueOptions{Min: 10, Max: 20}, want: 10},
+		{name: "stored value of another type is ignored", opts: DialValueOptions{Min: 0, Max: 100, Default: 40, SettingsKey: "volume"}, settings: ActionSettings{"volume": "loud"}, want: 40},
+		{name: "empty range", opts: DialValueOptions{Min: 5, Max: 5}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inst := &Instance{Settings: tt.settings}
+			inst.Context = "dial"
+			d, err := inst.BindDialValue(tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("got no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := d.Value(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialValueClamp(t *testing.T) {
+	d := &DialValue{opts: DialValueOptions{Min: -10, Max: 10}}
+	for value, want := range map[float64]float64{-11: -10, -10: -10, 0: 0, 9.5: 9.5, 10: 10, 1e9: 10} {
+		if got := d.clamp(value); got != want {
+			t.Errorf("clamp(%v) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestDialValueAcceleration(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	tests := []struct {
+		name         string
+		acceleration float64
+		since        time.Duration // Time since the previous rotation.
+		want         float64
+	}{
+		{name: "disabled", acceleration: 0, since: 0, want: 1},
+		{name: "disabled at 1", acceleration: 1, since: 0, want: 1},
+		{name: "same instant", acceleration: 5, since: 0, want: 5},
+		{name: "half the window", acceleration: 5, since: 50 * time.Millisecond, want: 3},
+		{name: "end of the window", acceleration: 5, since: 100 * time.Millisecond, want: 1},
+		{name: "after the window", acceleration: 5, since: time.Second, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &DialValue{opts: DialValueOptions{Acceleration: tt.acceleration, AccelerationWindow: 100 * time.Millisecond}}
+			d.acceleration(start)
+			if got := d.acceleration(start.Add(tt.since)); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The first rotation has nothing to be fast relative to.
+	d := &DialValue{opts: DialValueOptions{Acceleration: 5, AccelerationWindow: 100 * time.Millisecond}}
+	if got := d.acceleration(start); got != 1 {
+		t.Errorf("got %v for the first rotation, want 1", got)
+	}
+}
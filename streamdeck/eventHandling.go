@@ -28,12 +28,14 @@ func DispatchEvent(event StreamDeckEvent) {
 				}); ok {
 					handler.HandleDidReceiveSettings(e)
 				}
+				notifySettingsChanged(action, e)
 			case *KeyDownEvent:
 				if handler, ok := action.(interface {
 					HandleKeyDown(*KeyDownEvent)
 				}); ok {
 					handler.HandleKeyDown(e)
 				}
+				recognizeKeyDown(action, e)
 
 			case *KeyUpEvent:
 				if handler, ok := action.(interface {
@@ -41,6 +43,7 @@ func DispatchEvent(event StreamDeckEvent) {
 				}); ok {
 					handler.HandleKeyUp(e)
 				}
+				recognizeKeyUp(action, e)
 			case *WillAppearEvent:
 				if handler, ok := action.(interface {
 					HandleWillAppear(*WillAppearEvent)
@@ -65,6 +68,7 @@ func DispatchEvent(event StreamDeckEvent) {
 				}); ok {
 					handler.HandleTouchTap(e)
 				}
+				routeTouchTap(action, e)
 			case *DialDownEvent:
 				if handler, ok := action.(interface {
 					HandleDialDown(*DialDownEvent)
@@ -114,6 +118,13 @@ func DispatchEvent(event StreamDeckEvent) {
 			log.Printf("Failed to cast event to ActionAssociatedEvent type")
 		}
 	} else {
+		// Deep links go to a single route once the router is in use
+		if e, ok := event.(*DidReceiveDeepLinkEvent); ok && Plugin.DeepLinks().Dispatch(e.Payload.Url) {
+			return
+		}
+
+		notifyApplication(event)
+
 		// Handle global event
 		for _, action := range actionRegistry {
 			switch e := event.(type) {
@@ -159,6 +170,8 @@ func DispatchEvent(event StreamDeckEvent) {
 				}); ok {
 					handler.HandleSystemDidWakeUp(e)
 				}
+			case *DidReceiveSecretsEvent:
+				// Only ever the answer to GetSecret.
 			default:
 				log.Printf("No handler found for global event type: %T", e)
 			}
@@ -176,12 +189,43 @@ func HandleEvent(data []byte) {
 	e := event.GetEventType()
 	log.Printf("SD -> %s", e)
 
+	trackEvent(event)
+	routeResponse(event)
+
+	scheduleDispatch(event)
+}
+
+// trackEvent prepares incoming settings and keeps the SDK's registries up to date before
+// an event reaches the actions.
+func trackEvent(event StreamDeckEvent) {
+	// An instance that appears again must stop counting as gone before the settings
+	// checks send it alerts.
+	trackGoneContexts(event)
+	prepareEventSettings(event)
+
+	trackDevice(event)
+	rememberPreviousSettings(event)
+	trackInstance(event)
+	trackState(event)
+	trackApplication(event)
+
+	if e, ok := event.(*DidReceiveGlobalSettingsEvent); ok {
+		storeGlobalSettings(e.Payload.Settings)
+	}
+
+	if _, ok := event.(*SystemDidWakeUpEvent); ok {
+		resumeAfterWake()
+	}
+}
+
+// routeResponse hands events that answer a pending request to whoever is waiting on them.
+func routeResponse(event StreamDeckEvent) {
 	switch ev := event.(type) {
 	case *DidReceiveSettingsEvent:
-		go sendResponse(ev.GetContext(), ev)
+		sendResponse(ev.GetContext(), ev)
 	case *DidReceiveGlobalSettingsEvent:
-		go sendResponse(PluginConfig.PluginUUID, ev)
+		sendResponse(PluginConfig.PluginUUID, ev)
+	case *DidReceiveSecretsEvent:
+		sendResponse(secretsRequestKey, ev)
 	}
-
-	go DispatchEvent(event)
 }
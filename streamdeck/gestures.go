@@ -0,0 +1,254 @@
+package streamdeck
+
+import (
+	"sync"
+	"time"
+)
+
+// GestureOptions configures gesture recognition for an action. Zero values fall back to
+// the defaults below.
+type GestureOptions struct {
+	LongPress  time.Duration // How long a key must be held to count as a long press. Defaults to 500ms.
+	DoubleTap  time.Duration // How long to wait for a second tap. Defaults to 300ms.
+	HoldRepeat time.Duration // Interval of HandleHoldRepeat once a long press fired. Defaults to 150ms.
+}
+
+const (
+	defaultLongPress  = 500 * time.Millisecond
+	defaultDoubleTap  = 300 * time.Millisecond
+	defaultHoldRepeat = 150 * time.Millisecond
+)
+
+// GestureAction is implemented by actions that opt in to gesture recognition. On top of
+// the plain HandleKeyDown and HandleKeyUp, such actions can implement any of:
+//
+//	HandleShortPress(*KeyUpEvent)
+//	HandleLongPress(*KeyDownEvent)
+//	HandleDoubleTap(*KeyUpEvent)
+//	HandleHoldRepeat(*KeyDownEvent, int)
+//
+// HandleShortPress is suppressed when a long press or double tap fires. When the action
+// doesn't implement HandleDoubleTap, short presses are reported without waiting for a
+// second tap.
+//
+// Usage:
+//
+//	func (a *MyAction) GestureOptions() streamdeck.GestureOptions {
+//		return streamdeck.GestureOptions{LongPress: time.Second}
+//	}
+type GestureAction interface {
+	Action
+	GestureOptions() GestureOptions
+}
+
+type gestureState struct {
+	mu          sync.Mutex
+	down        *KeyDownEvent
+	holdTimer   clockTimer
+	holdGen     int
+	longFired   bool
+	repeatCount int
+	tapTimer    clockTimer
+	tapGen      int
+	pendingTap  *KeyUpEvent
+}
+
+var (
+	gestureStates = make(map[string]*gestureState)
+	gestureMutex  sync.Mutex
+)
+
+func getGestureState(context string) *gestureState {
+	gestureMutex.Lock()
+	defer gestureMutex.Unlock()
+
+	st, ok := gestureStates[context]
+	if !ok {
+		st = &gestureState{}
+		gestureStates[context] = st
+	}
+	return st
+}
+
+func forgetGestures(context string) {
+	gestureMutex.Lock()
+	st, ok := gestureStates[context]
+	delete(gestureStates, context)
+	gestureMutex.Unlock()
+
+	if ok {
+		st.mu.Lock()
+		st.holdGen++
+		st.tapGen++
+		if st.holdTimer != nil {
+			st.holdTimer.Stop()
+		}
+		if st.tapTimer != nil {
+			st.tapTimer.Stop()
+		}
+		st.mu.Unlock()
+	}
+}
+
+// resetGestures forgets the gestures of every instance.
+func resetGestures() {
+	gestureMutex.Lock()
+	contexts := make([]string, 0, len(gestureStates))
+	for context := range gestureStates {
+		contexts = append(contexts, context)
+	}
+	gestureMutex.Unlock()
+
+	for _, context := range contexts {
+		forgetGestures(context)
+	}
+}
+
+func gestureOptionsFor(action GestureAction) GestureOptions {
+	opts := action.GestureOptions()
+	if opts.LongPress <= 0 {
+		opts.LongPress = defaultLongPress
+	}
+	if opts.DoubleTap <= 0 {
+		opts.DoubleTap = defaultDoubleTap
+	}
+	if opts.HoldRepeat <= 0 {
+		opts.HoldRepeat = defaultHoldRepeat
+	}
+	return opts
+}
+
+func recognizeKeyDown(action Action, event *KeyDownEvent) {
+	gestureAction, ok := action.(GestureAction)
+	if !ok {
+		return
+	}
+	opts := gestureOptionsFor(gestureAction)
+	st := getGestureState(event.Context)
+
+	st.mu.Lock()
+	st.down = event
+	st.longFired = false
+	st.repeatCount = 0
+	st.holdGen++
+	generation := st.holdGen
+	st.holdTimer = afterFunc(opts.LongPress, func() {
+		enqueue(event.Context, func() { onGestureHold(action, st, generation, opts) })
+	})
+	st.mu.Unlock()
+}
+
+func recognizeKeyUp(action Action, event *KeyUpEvent) {
+	gestureAction, ok := action.(GestureAction)
+	if !ok {
+		return
+	}
+	opts := gestureOptionsFor(gestureAction)
+	st := getGestureState(event.Context)
+
+	st.mu.Lock()
+	st.down = nil
+	st.holdGen++
+	if st.holdTimer != nil {
+		st.holdTimer.Stop()
+	}
+	if st.longFired {
+		st.mu.Unlock()
+		return
+	}
+
+	doubleTapHandler, wantsDoubleTap := action.(interface {
+		HandleDoubleTap(*KeyUpEvent)
+	})
+	if !wantsDoubleTap {
+		st.mu.Unlock()
+		emitShortPress(action, event)
+		return
+	}
+
+	if st.pendingTap != nil {
+		st.pendingTap = nil
+		st.tapGen++
+		st.tapTimer.Stop()
+		st.mu.Unlock()
+		doubleTapHandler.HandleDoubleTap(event)
+		return
+	}
+
+	st.pendingTap = event
+	st.tapGen++
+	generation := st.tapGen
+	st.tapTimer = afterFunc(opts.DoubleTap, func() {
+		enqueue(event.Context, func() { onGestureTapTimeout(action, st, generation) })
+	})
+	st.mu.Unlock()
+}
+
+func onGestureHold(action Action, st *gestureState, generation int, opts GestureOptions) {
+	st.mu.Lock()
+	if generation != st.holdGen || st.down == nil {
+		st.mu.Unlock()
+		return
+	}
+	down := st.down
+	first := !st.longFired
+	st.longFired = true
+	if !first {
+		st.repeatCount++
+	}
+	count := st.repeatCount
+
+	// A tap still waiting for its partner was a short press after all.
+	pendingTap := st.pendingTap
+	st.pendingTap = nil
+	st.tapGen++
+
+	_, wantsRepeat := action.(interface {
+		HandleHoldRepeat(*KeyDownEvent, int)
+	})
+	if wantsRepeat {
+		st.holdTimer = afterFunc(opts.HoldRepeat, func() {
+			enqueue(down.Context, func() { onGestureHold(action, st, generation, opts) })
+		})
+	}
+	st.mu.Unlock()
+
+	if pendingTap != nil {
+		emitShortPress(action, pendingTap)
+	}
+
+	if first {
+		if handler, ok := action.(interface {
+			HandleLongPress(*KeyDownEvent)
+		}); ok {
+			handler.HandleLongPress(down)
+		}
+		return
+	}
+	if handler, ok := action.(interface {
+		HandleHoldRepeat(*KeyDownEvent, int)
+	}); ok {
+		handler.HandleHoldRepeat(down, count)
+	}
+}
+
+func onGestureTapTimeout(action Action, st *gestureState, generation int) {
+	st.mu.Lock()
+	if generation != st.tapGen || st.pendingTap == nil {
+		st.mu.Unlock()
+		return
+	}
+	tap := st.pendingTap
+	st.pendingTap = nil
+	st.mu.Unlock()
+
+	emitShortPress(action, tap)
+}
+
+func emitShortPress(action Action, event *KeyUpEvent) {
+	if handler, ok := action.(interface {
+		HandleShortPress(*KeyUpEvent)
+	}); ok {
+		handler.HandleShortPress(event)
+	}
+}
@@ -0,0 +1,191 @@
+package streamdeck
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// gestureRecorder reports the gestures it receives on a channel. Gestures recognized by a
+// timer arrive from the instance queue, so tests wait for them rather than sleep.
+type gestureRecorder struct {
+	ActionConfig
+	opts     GestureOptions
+	gestures chan string
+}
+
+func (a *gestureRecorder) GestureOptions() GestureOptions {
+	return a.opts
+}
+
+func (a *gestureRecorder) HandleShortPress(*KeyUpEvent) {
+	a.gestures <- "short"
+}
+
+func (a *gestureRecorder) HandleLongPress(*KeyDownEvent) {
+	a.gestures <- "long"
+}
+
+func (a *gestureRecorder) HandleHoldRepeat(_ *KeyDownEvent, count int) {
+	a.gestures <- "repeat " + strconv.Itoa(count)
+}
+
+type doubleTapRecorder struct {
+	gestureRecorder
+}
+
+func (a *doubleTapRecorder) HandleDoubleTap(*KeyUpEvent) {
+	a.gestures <- "double"
+}
+
+// startGestureTest registers the action and shows an instance of it for the test.
+func startGestureTest(t *testing.T, action Action) string {
+	t.Helper()
+	context := "gesture-" + t.Name()
+	RegisterAction(action)
+	openInstanceQueue(context)
+	t.Cleanup(func() { closeInstanceQueue(context) })
+	return context
+}
+
+func pressKey(t *testing.T, eventType, actionUUID, context string) {
+	t.Helper()
+	event, err := ParseEvent([]byte(fmt.Sprintf(
+		`{"event":%q,"action":%q,"context":%q,"device":"device","payload":{"settings":{},"coordinates":{"column":0,"row":0},"state":0}}`,
+		eventType, actionUUID, context,
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	DispatchEvent(event)
+}
+
+func waitGestures(t *testing.T, gestures chan string, n int) []string {
+	t.Helper()
+	var got []string
+	for len(got) < n {
+		select {
+		case gesture := <-gestures:
+			got = append(got, gesture)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("got gestures %v, timed out waiting for %d", got, n)
+		}
+	}
+	return got
+}
+
+func expectNoGesture(t *testing.T, gestures chan string) {
+	t.Helper()
+	select {
+	case gesture := <-gestures:
+		t.Errorf("got unexpected gesture %s", gesture)
+	default:
+	}
+}
+
+func TestGestureShortPress(t *testing.T) {
+	action := &gestureRecorder{
+		ActionConfig: ActionConfig{UUID: "com.example.gesture.short"},
+		opts:         GestureOptions{LongPress: time.Hour},
+		gestures:     make(chan string, 16),
+	}
+	context := startGestureTest(t, action)
+
+	// Without HandleDoubleTap, the short press is reported on key up, without waiting.
+	pressKey(t, "keyDown", action.UUID, context)
+	pressKey(t, "keyUp", action.UUID, context)
+	if got := waitGestures(t, action.gestures, 1); got[0] != "short" {
+		t.Errorf("got %s, want short", got[0])
+	}
+}
+
+func TestGestureDoubleTap(t *testing.T) {
+	action := &doubleTapRecorder{gestureRecorder{
+		ActionConfig: ActionConfig{UUID: "com.example.gesture.double"},
+		opts:         GestureOptions{LongPress: time.Hour, DoubleTap: time.Hour},
+		gestures:     make(chan string, 16),
+	}}
+	context := startGestureTest(t, action)
+
+	for i := 0; i < 2; i++ {
+		pressKey(t, "keyDown", action.UUID, context)
+		pressKey(t, "keyUp", action.UUID, context)
+	}
+	if got := waitGestures(t, action.gestures, 1); got[0] != "double" {
+		t.Errorf("got %s, want double", got[0])
+	}
+	expectNoGesture(t, action.gestures)
+}
+
+func TestGestureTapTimeout(t *testing.T) {
+	action := &doubleTapRecorder{gestureRecorder{
+		ActionConfig: ActionConfig{UUID: "com.example.gesture.timeout"},
+		opts:         GestureOptions{LongPress: time.Hour, DoubleTap: 10 * time.Millisecond},
+		gestures:     make(chan string, 16),
+	}}
+	context := startGestureTest(t, action)
+
+	pressKey(t, "keyDown", action.UUID, context)
+	pressKey(t, "keyUp", action.UUID, context)
+	if got := waitGestures(t, action.gestures, 1); got[0] != "short" {
+		t.Errorf("got %s, want short", got[0])
+	}
+}
+
+func TestGestureLongPressSuppressesShortPress(t *testing.T) {
+	action := &gestureRecorder{
+		ActionConfig: ActionConfig{UUID: "com.example.gesture.long"},
+		opts:         GestureOptions{LongPress: 10 * time.Millisecond, HoldRepeat: time.Hour},
+		gestures:     make(chan string, 16),
+	}
+	context := startGestureTest(t, action)
+
+	pressKey(t, "keyDown", action.UUID, context)
+	if got := waitGestures(t, action.gestures, 1); got[0] != "long" {
+		t.Fatalf("got %s, want long", got[0])
+	}
+	pressKey(t, "keyUp", action.UUID, context)
+	expectNoGesture(t, action.gestures)
+}
+
+func TestGestureHoldRepeat(t *testing.T) {
+	action := &gestureRecorder{
+		ActionConfig: ActionConfig{UUID: "com.example.gesture.repeat"},
+		opts:         GestureOptions{LongPress: 10 * time.Millisecond, HoldRepeat: 10 * time.Millisecond},
+		gestures:     make(chan string, 64),
+	}
+	context := startGestureTest(t, action)
+
+	pressKey(t, "keyDown", action.UUID, context)
+	got := waitGestures(t, action.gestures, 4)
+	pressKey(t, "keyUp", action.UUID, context)
+
+	want := []string{"long", "repeat 1", "repeat 2", "repeat 3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestGesturePendingTapBeforeLongPress(t *testing.T) {
+	action := &doubleTapRecorder{gestureRecorder{
+		ActionConfig: ActionConfig{UUID: "com.example.gesture.pending"},
+		opts:         GestureOptions{LongPress: 10 * time.Millisecond, DoubleTap: time.Hour, HoldRepeat: time.Hour},
+		gestures:     make(chan string, 16),
+	}}
+	context := startGestureTest(t, action)
+
+	// A tap, then a press held into a long press: the tap was a short press after all.
+	pressKey(t, "keyDown", action.UUID, context)
+	pressKey(t, "keyUp", action.UUID, context)
+	pressKey(t, "keyDown", action.UUID, context)
+	got := waitGestures(t, action.gestures, 2)
+	pressKey(t, "keyUp", action.UUID, context)
+
+	want := []string{"short", "long"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	expectNoGesture(t, action.gestures)
+}
@@ -0,0 +1,53 @@
+package streamdeck
+
+// Logs a message to the file-system.
+//
+// Usage:
+//
+//	e.LogMessage("System woke up")
+//
+// Docs:
+// https://docs.elgato.com/sdk/plugins/events-sent#logmessage
+func (e *GlobalEvent) LogMessage(message string) error {
+	return Plugin.LogMessage(message)
+}
+
+// Opens the URL in the user's default browser.
+//
+// Usage:
+//
+//	e.OpenUrl("https://www.example.com")
+//
+// Docs:
+// https://docs.elgato.com/sdk/plugins/events-sent#openurl
+func (e *GlobalEvent) OpenUrl(url string) error {
+	return Plugin.OpenUrl(url)
+}
+
+// Saves the plugin's global settings.
+//
+// Usage:
+//
+//	e.SetGlobalSettings(map[string]any{
+//		"lastWake": time.Now().Unix(),
+//	})
+//
+// Docs:
+// https://docs.elgato.com/sdk/plugins/events-sent#setglobalsettings
+func (e *GlobalEvent) SetGlobalSettings(settings map[string]any) error {
+	return Plugin.SetGlobalSettings(settings)
+}
+
+// Switches to the profile, as distributed by the plugin, on the given device.
+//
+// Usage:
+//
+//	func (a *MyAction) HandleDeviceDidConnect(e *streamdeck.DeviceDidConnectEvent) {
+//		e.SwitchToProfile(e.Device, "MyPluginProfile")
+//	}
+//
+// Docs:
+// https://docs.elgato.com/sdk/plugins/events-sent#switchtoprofile
+func (e *GlobalEvent) SwitchToProfile(device, profile string, page ...uint8) error {
+	return Plugin.SwitchToProfile(device, profile, page...)
+}
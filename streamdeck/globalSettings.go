@@ -0,0 +1,215 @@
+package streamdeck
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+)
+
+// globalSettingsStore caches the plugin's global settings. It is filled by the
+// getGlobalSettings request sent at startup and kept fresh from didReceiveGlobalSettings.
+type globalSettingsStore struct {
+	mu          sync.Mutex
+	settings    GlobalSettings
+	loaded      bool
+	subscribers map[int]func(GlobalSettings)
+	nextID      int
+
+	// updateMu serialises Update, which can't hold mu while the caller's function runs.
+	updateMu sync.Mutex
+	// notify runs the subscribers in order, off the WebSocket reader goroutine, so they
+	// can send requests and wait for the answers.
+	notify *instanceQueue
+}
+
+var globalStore = &globalSettingsStore{
+	subscribers: make(map[int]func(GlobalSettings)),
+	notify:      newInstanceQueue(),
+}
+
+// GlobalStore is a typed view of the cached global settings. T is usually a struct with
+// json tags, but GlobalSettings works too.
+type GlobalStore[T any] struct{}
+
+// Returns a typed view of the plugin's global settings.
+//
+// Usage:
+//
+//	type Credentials struct {
+//		Token string `json:"token"`
+//	}
+//
+//	creds, err := streamdeck.Global[Credentials]().Get()
+func Global[T any]() *GlobalStore[T] {
+	return &GlobalStore[T]{}
+}
+
+// Returns the cached global settings. Until the Stream Deck has answered the request sent
+// at startup, this is the zero value of T; use Loaded or Subscribe to wait for them.
+func (g *GlobalStore[T]) Get() (T, error) {
+	globalStore.mu.Lock()
+	settings := globalStore.settings
+	globalStore.mu.Unlock()
+	return decodeGlobalSettings[T](settings)
+}
+
+// Reports whether the global settings have been received from the Stream Deck.
+func (g *GlobalStore[T]) Loaded() bool {
+	globalStore.mu.Lock()
+	defer globalStore.mu.Unlock()
+	return globalStore.loaded
+}
+
+// Changes the global settings and saves them. Concurrent updates are applied one after
+// the other, so none of them are lost. Keys T doesn't declare are kept as they are, so
+// typed views of different parts of the global settings don't overwrite each other.
+//
+// fn runs without the cache locked, so it may call Get, but not Update.
+//
+// Usage:
+//
+//	err := streamdeck.Global[Credentials]().Update(func(c *Credentials) {
+//		c.Token = token
+//	})
+func (g *GlobalStore[T]) Update(fn func(*T)) error {
+	globalStore.updateMu.Lock()
+	defer globalStore.updateMu.Unlock()
+
+	globalStore.mu.Lock()
+	current := globalStore.settings
+	globalStore.mu.Unlock()
+
+	value, err := decodeGlobalSettings[T](current)
+	if err != nil {
+		return err
+	}
+	fn(&value)
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var fields GlobalSettings
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	settings := make(GlobalSettings, len(current)+len(fields))
+	for key, v := range current {
+		settings[key] = v
+	}
+	for key, v := range fields {
+		settings[key] = v
+	}
+
+	response := SetGlobalSettingsCommand{
+		Event:   "setGlobalSettings",
+		Context: PluginConfig.PluginUUID,
+		Payload: settings,
+	}
+	if err := SendEventToStreamDeck(response); err != nil {
+		return err
+	}
+	storeGlobalSettings(settings)
+	return nil
+}
+
+// Calls fn whenever the global settings change, whether from the property inspector or
+// from the plugin. The returned function removes the subscription.
+//
+// Usage:
+//
+//	unsubscribe := streamdeck.Global[Credentials]().Subscribe(func(c Credentials) {
+//		client.SetToken(c.Token)
+//	})
+func (g *GlobalStore[T]) Subscribe(fn func(T)) func() {
+	globalStore.mu.Lock()
+	defer globalStore.mu.Unlock()
+
+	id := globalStore.nextID
+	globalStore.nextID++
+	globalStore.subscribers[id] = func(settings GlobalSettings) {
+		value, err := decodeGlobalSettings[T](settings)
+		if err != nil {
+			log.Printf("Error decoding global settings: %v", err)
+			return
+		}
+		fn(value)
+	}
+
+	return func() {
+		globalStore.mu.Lock()
+		defer globalStore.mu.Unlock()
+		delete(globalStore.subscribers, id)
+	}
+}
+
+func decodeGlobalSettings[T any](settings GlobalSettings) (T, error) {
+	var value T
+	if settings == nil {
+		return value, nil
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return value, err
+	}
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
+
+// subscriberList must be called with s.mu held.
+func (s *globalSettingsStore) subscriberList() []func(GlobalSettings) {
+	subscribers := make([]func(GlobalSettings), 0, len(s.subscribers))
+	for _, fn := range s.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	return subscribers
+}
+
+func notifyGlobalSubscribers(subscribers []func(GlobalSettings), settings GlobalSettings) {
+	if len(subscribers) == 0 {
+		return
+	}
+	globalStore.mu.Lock()
+	notify := globalStore.notify
+	globalStore.mu.Unlock()
+
+	notify.push(func() {
+		for _, fn := range subscribers {
+			fn(settings)
+		}
+	})
+}
+
+// storeGlobalSettings replaces the cached global settings and queues the subscribers.
+func storeGlobalSettings(settings GlobalSettings) {
+	globalStore.mu.Lock()
+	globalStore.settings = settings
+	globalStore.loaded = true
+	subscribers := globalStore.subscriberList()
+	globalStore.mu.Unlock()
+
+	notifyGlobalSubscribers(subscribers, settings)
+}
+
+// resetGlobalSettings empties the cache and replaces the subscriber queue, keeping the
+// subscribers.
+func resetGlobalSettings() {
+	globalStore.mu.Lock()
+	globalStore.settings = nil
+	globalStore.loaded = false
+	notify := globalStore.notify
+	globalStore.notify = newInstanceQueue()
+	globalStore.mu.Unlock()
+
+	notify.close()
+}
+
+// requestGlobalSettings asks the Stream Deck for the global settings. The answer arrives
+// as didReceiveGlobalSettings and fills the cache.
+func requestGlobalSettings() error {
+	response := GetGlobalSettingsCommand{
+		Event:   "getGlobalSettings",
+		Context: PluginConfig.PluginUUID,
+	}
+	return SendEventToStreamDeck(response)
+}
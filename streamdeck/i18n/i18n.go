@@ -0,0 +1,265 @@
+// Package i18n translates strings using the <lang>.json localization files shipped in the
+// plugin bundle.
+//
+// Strings are read from the Localization map of each file, e.g. de.json:
+//
+//	{
+//		"Localization": {
+//			"Muted": "Stumm",
+//			"Tracks.one": "%d Titel",
+//			"Tracks.other": "%d Titel"
+//		}
+//	}
+//
+// Docs:
+// https://docs.elgato.com/streamdeck/sdk/guides/localization
+package i18n
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/emilyxfox/go-streamdeck-sdk/streamdeck"
+)
+
+// FallbackLanguage is used for strings missing from the user's language.
+const FallbackLanguage = "en"
+
+// Count marks the argument of T that selects the plural form of a string. It is
+// formatted like any other int.
+//
+// Usage:
+//
+//	i18n.T("Tracks", i18n.Count(len(tracks)))
+type Count int
+
+// Catalog holds the strings of one language, and of the languages it falls back to.
+type Catalog struct {
+	language string
+	chain    []map[string]string // Requested language first, fallback last.
+}
+
+// Loads the strings for the language from the localization files in the bundle directory.
+// A regional language such as zh_CN falls back to zh, and then every language falls back
+// to FallbackLanguage. Missing files are skipped, but a file that can't be parsed is an error.
+//
+// Usage:
+//
+//	catalog, err := i18n.Load("com.example.mixer.sdPlugin", "de")
+func Load(bundleDir, language string) (*Catalog, error) {
+	c := &Catalog{language: language}
+
+	for _, lang := range fallbackChain(language) {
+		messages, err := loadFile(filepath.Join(bundleDir, lang+".json"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.chain = append(c.chain, messages)
+	}
+	return c, nil
+}
+
+func fallbackChain(language string) []string {
+	var chain []string
+	add := func(lang string) {
+		for _, l := range chain {
+			if l == lang {
+				return
+			}
+		}
+		chain = append(chain, lang)
+	}
+
+	if language != "" {
+		add(language)
+		if base, _, ok := strings.Cut(strings.ReplaceAll(language, "-", "_"), "_"); ok {
+			add(base)
+		}
+	}
+	add(FallbackLanguage)
+	return chain
+}
+
+func loadFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Localization map[string]string `json:"Localization"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if file.Localization == nil {
+		return map[string]string{}, nil
+	}
+	return file.Localization, nil
+}
+
+// Returns the language the catalog was loaded for.
+func (c *Catalog) Language() string {
+	return c.language
+}
+
+// Translates the key, formatting the args into it with fmt.Sprintf. When one of the args
+// is a Count, the plural form of the key is used: key.zero, key.one or key.other, as the
+// language's rules pick, falling back to key.other and then to the key itself. Keys
+// missing from every language are returned untranslated.
+//
+// Usage:
+//
+//	e.SetTitle(catalog.T("Muted"))
+//	e.SetTitle(catalog.T("Tracks", i18n.Count(12)))
+func (c *Catalog) T(key string, args ...any) string {
+	message := key
+
+	if count, ok := countArg(args); ok {
+		if m, ok := c.plural(key, count); ok {
+			message = m
+		} else if m, ok := c.lookup(key); ok {
+			message = m
+		}
+	} else if m, ok := c.lookup(key); ok {
+		message = m
+	}
+
+	// Forms such as "One track" take no verbs, so only format when there's a verb to fill.
+	if len(args) == 0 || !strings.Contains(message, "%") {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+func (c *Catalog) lookup(key string) (string, bool) {
+	for _, messages := range c.chain {
+		if message, ok := messages[key]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+func (c *Catalog) plural(key string, n Count) (string, bool) {
+	forms := []string{pluralForm(c.language, int(n)), "other"}
+	if n == 0 {
+		forms = append([]string{"zero"}, forms...)
+	}
+	for _, form := range forms {
+		if message, ok := c.lookup(key + "." + form); ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+func countArg(args []any) (Count, bool) {
+	for _, arg := range args {
+		if n, ok := arg.(Count); ok {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// pluralForm picks the CLDR plural category for n among the languages the Stream Deck
+// application is translated into.
+func pluralForm(language string, n int) string {
+	base, _, _ := strings.Cut(strings.ReplaceAll(language, "-", "_"), "_")
+	switch base {
+	case "ja", "ko", "zh":
+		return "other"
+	case "fr":
+		if n == 0 || n == 1 {
+			return "one"
+		}
+		return "other"
+	default:
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
+
+var (
+	defaultCatalog  *Catalog
+	fallbackCatalog *Catalog // Used by T until the language is known.
+	defaultMutex    sync.RWMutex
+)
+
+// Loads the catalog used by T, in the language of the Stream Deck application. Call it
+// once the plugin has started, as the language comes from the -info launch argument.
+//
+// Usage:
+//
+//	if err := i18n.Init("."); err != nil {
+//		log.Printf("Error loading translations: %v", err)
+//	}
+func Init(bundleDir string) error {
+	catalog, err := Load(bundleDir, streamdeck.PluginConfig.Info.Application.Language)
+	if err != nil {
+		return err
+	}
+	defaultMutex.Lock()
+	defaultCatalog = catalog
+	defaultMutex.Unlock()
+	return nil
+}
+
+// Translates the key with the catalog loaded by Init. The plugin runs from inside its
+// bundle, so when Init hasn't been called the catalog is loaded from the working directory.
+// Strings translated before the plugin has started, when the language of the Stream Deck
+// application isn't known yet, use a FallbackLanguage catalog that is replaced once it is.
+//
+// Usage:
+//
+//	e.ShowAlert()
+//	e.SetTitle(i18n.T("Offline"))
+func T(key string, args ...any) string {
+	defaultMutex.RLock()
+	catalog := defaultCatalog
+	defaultMutex.RUnlock()
+
+	if catalog == nil {
+		catalog = loadDefault()
+	}
+	return catalog.T(key, args...)
+}
+
+// loadDefault loads the catalog for T when Init hasn't been called.
+func loadDefault() *Catalog {
+	language := streamdeck.PluginConfig.Info.Application.Language
+
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	if defaultCatalog != nil {
+		return defaultCatalog
+	}
+	if language == "" && fallbackCatalog != nil {
+		return fallbackCatalog
+	}
+
+	catalog, err := Load(".", language)
+	if err != nil {
+		log.Printf("Error loading translations: %v", err)
+		catalog = &Catalog{language: language}
+	}
+	if language == "" {
+		fallbackCatalog = catalog
+	} else {
+		defaultCatalog = catalog
+		fallbackCatalog = nil
+	}
+	return catalog
+}
@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFallbackChain(t *testing.T) {
+	tests := []struct {
+		language string
+		want     []string
+	}{
+		{language: "zh_CN", want: []string{"zh_CN", "zh", "en"}},
+		{language: "pt-BR", want: []string{"pt-BR", "pt", "en"}},
+		{language: "de", want: []string{"de", "en"}},
+		{language: "en", want: []string{"en"}},
+		{language: "en_GB", want: []string{"en_GB", "en"}},
+		{language: "", want: []string{"en"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			if got := fallbackChain(tt.language); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func writeLocalization(t *testing.T, dir, language, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, language+".json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCatalogT(t *testing.T) {
+	dir := t.TempDir()
+	writeLocalization(t, dir, "en", `{"Localization": {
+		"Muted": "Muted",
+		"Offline": "Offline",
+		"Tracks.one": "One track",
+		"Tracks.other": "%d tracks",
+		"Files.zero": "No files",
+		"Files.one": "%d file",
+		"Files.other": "%d files"
+	}}`)
+	writeLocalization(t, dir, "fr", `{"Localization": {
+		"Muted": "Muet",
+		"Tracks.one": "%d piste",
+		"Tracks.other": "%d pistes"
+	}}`)
+	writeLocalization(t, dir, "zh", `{"Localization": {"Muted": "静音"}}`)
+
+	tests := []struct {
+		name     string
+		language string
+		key      string
+		args     []any
+		want     string
+	}{
+		{name: "translated", language: "fr", key: "Muted", want: "Muet"},
+		{name: "regional falls back to base", language: "zh_CN", key: "Muted", want: "静音"},
+		{name: "falls back to english", language: "fr", key: "Offline", want: "Offline"},
+		{name: "missing key", language: "fr", key: "Unknown key", want: "Unknown key"},
+		{name: "missing key with args", language: "fr", key: "Unknown %d", args: []any{3}, want: "Unknown 3"},
+		{name: "english one", language: "en", key: "Tracks", args: []any{Count(1)}, want: "One track"},
+		{name: "english zero is other", language: "en", key: "Tracks", args: []any{Count(0)}, want: "0 tracks"},
+		{name: "english other", language: "en", key: "Tracks", args: []any{Count(12)}, want: "12 tracks"},
+		{name: "french zero is one", language: "fr", key: "Tracks", args: []any{Count(0)}, want: "0 piste"},
+		{name: "french one", language: "fr", key: "Tracks", args: []any{Count(1)}, want: "1 piste"},
+		{name: "french other", language: "fr", key: "Tracks", args: []any{Count(2)}, want: "2 pistes"},
+		{name: "zero form takes precedence", language: "fr", key: "Files", args: []any{Count(0)}, want: "No files"},
+		{name: "chinese has no one form", language: "zh", key: "Files", args: []any{Count(1)}, want: "1 files"},
+		{name: "missing plural", language: "en", key: "Muted", args: []any{Count(2)}, want: "Muted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog, err := Load(dir, tt.language)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := catalog.T(tt.key, tt.args...); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMalformedFile(t *testing.T) {
+	dir := t.TempDir()
+	writeLocalization(t, dir, "en", `{"Localization": `)
+	if _, err := Load(dir, "de"); err == nil {
+		t.Error("got no error for a malformed localization file")
+	}
+}
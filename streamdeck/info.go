@@ -0,0 +1,238 @@
+package streamdeck
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// Platform is the operating system the Stream Deck application runs on.
+type Platform string
+
+const (
+	PlatformMac     Platform = "mac"
+	PlatformWindows Platform = "windows"
+)
+
+// Feature names accepted by Info.SupportsFeature.
+const (
+	FeatureDials                 = "dials"                 // Stream Deck + dials and touch strip.
+	FeatureSetTriggerDescription = "setTriggerDescription" // Trigger descriptions of dials.
+	FeatureSDKVersion2           = "sdkVersion2"           // Manifests declaring SDKVersion 2.
+	FeatureDeepLinks             = "deepLinks"             // Deep links to the plugin.
+	FeatureSecrets               = "secrets"               // getSecrets and didReceiveSecrets.
+)
+
+// featureVersions holds the first application version supporting each feature.
+var featureVersions = map[string]Version{
+	FeatureDials:                 {Major: 6, Minor: 0},
+	FeatureSetTriggerDescription: {Major: 6, Minor: 0},
+	FeatureSDKVersion2:           {Major: 6, Minor: 4},
+	FeatureDeepLinks:             {Major: 6, Minor: 5},
+	FeatureSecrets:               {Major: 6, Minor: 9},
+}
+
+// Info holds the parsed JSON data from the -info flag.
+//
+// Decoding is tolerant: fields of the wrong type are logged and skipped, and malformed
+// devices dropped, rather than failing the whole document.
+//
+// Docs:
+// https://docs.elgato.com/streamdeck/sdk/references/websocket/plugin/#registration-procedure
+type Info struct {
+	Application      ApplicationInfo `json:"application"`
+	Plugin           PluginInfo      `json:"plugin"`
+	DevicePixelRatio int             `json:"devicePixelRatio"`
+	Colors           Colors          `json:"colors"`
+	Devices          []DeviceInfo    `json:"devices"`
+}
+
+// StreamDeckInfo is the former name of Info.
+type StreamDeckInfo = Info
+
+// ApplicationInfo describes the Stream Deck application.
+type ApplicationInfo struct {
+	Font            string   `json:"font"`
+	Language        string   `json:"language"` // e.g. "en", "de" or "zh_CN".
+	Platform        Platform `json:"platform"`
+	PlatformVersion string   `json:"platformVersion"`
+	Version         string   `json:"version"` // e.g. "6.9.0.19876".
+}
+
+// PluginInfo describes the plugin, as declared in its manifest.
+type PluginInfo struct {
+	UUID    string `json:"uuid"`
+	Version string `json:"version"`
+}
+
+// Colors are the user's theme colours.
+type Colors struct {
+	ButtonMouseOverBackgroundColor Color `json:"buttonMouseOverBackgroundColor"`
+	ButtonPressedBackgroundColor   Color `json:"buttonPressedBackgroundColor"`
+	ButtonPressedBorderColor       Color `json:"buttonPressedBorderColor"`
+	ButtonPressedTextColor         Color `json:"buttonPressedTextColor"`
+	DisabledColor                  Color `json:"disabledColor"`
+	HighlightColor                 Color `json:"highlightColor"`
+	MouseDownColor                 Color `json:"mouseDownColor"`
+}
+
+// DeviceInfo describes a device as listed in the -info flag
+type DeviceInfo struct {
+	Id   string     `json:"id"`
+	Name string     `json:"name"`
+	Size DeviceSize `json:"size"`
+	Type DeviceType `json:"type"`
+}
+
+func (i *Info) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Application      json.RawMessage `json:"application"`
+		Plugin           json.RawMessage `json:"plugin"`
+		DevicePixelRatio json.RawMessage `json:"devicePixelRatio"`
+		Colors           json.RawMessage `json:"colors"`
+		Devices          json.RawMessage `json:"devices"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	decodeInfoSection("application", raw.Application, &i.Application)
+	decodeInfoSection("plugin", raw.Plugin, &i.Plugin)
+	decodeInfoSection("colors", raw.Colors, &i.Colors)
+
+	var ratio float64
+	decodeInfoSection("devicePixelRatio", raw.DevicePixelRatio, &ratio)
+	i.DevicePixelRatio = int(ratio)
+
+	var rawDevices []json.RawMessage
+	decodeInfoSection("devices", raw.Devices, &rawDevices)
+	i.Devices = nil
+	for _, rawDevice := range rawDevices {
+		var device DeviceInfo
+		if decodeInfoSection("device", rawDevice, &device) {
+			i.Devices = append(i.Devices, device)
+		}
+	}
+	return nil
+}
+
+func decodeInfoSection(name string, data json.RawMessage, v any) bool {
+	if len(data) == 0 {
+		return false
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		log.Printf("Ignoring malformed %s in info: %v", name, err)
+		return false
+	}
+	return true
+}
+
+// Reports whether the Stream Deck application runs on macOS.
+func (i Info) IsMac() bool {
+	return i.Application.Platform == PlatformMac
+}
+
+// Reports whether the Stream Deck application runs on Windows.
+func (i Info) IsWindows() bool {
+	return i.Application.Platform == PlatformWindows
+}
+
+// Returns the version of the Stream Deck application, which is zero when it can't be parsed.
+func (i Info) ApplicationVersion() Version {
+	version, err := ParseVersion(i.Application.Version)
+	if err != nil {
+		return Version{}
+	}
+	return version
+}
+
+// Returns the highest manifest SDKVersion the Stream Deck application accepts.
+func (i Info) SDKVersion() int {
+	if i.SupportsFeature(FeatureSDKVersion2) {
+		return 2
+	}
+	return 1
+}
+
+// Reports whether the Stream Deck application is recent enough for the feature, one of
+// the Feature constants. Unknown features are unsupported.
+//
+// Usage:
+//
+//	if streamdeck.PluginConfig.Info.SupportsFeature(streamdeck.FeatureDeepLinks) {
+//		...
+//	}
+func (i Info) SupportsFeature(name string) bool {
+	since, ok := featureVersions[name]
+	if !ok {
+		return false
+	}
+	return i.ApplicationVersion().AtLeast(since.Major, since.Minor)
+}
+
+// Version is a Stream Deck application version.
+type Version struct {
+	Major, Minor, Patch, Build int
+}
+
+// Parses a version such as "6.9" or "6.9.0.19876".
+func ParseVersion(s string) (Version, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 || len(parts) > 4 {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+	var numbers [4]int
+	for n, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || value < 0 {
+			return Version{}, fmt.Errorf("invalid version %q", s)
+		}
+		numbers[n] = value
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2], Build: numbers[3]}, nil
+}
+
+// Reports whether the version is major.minor or later.
+func (v Version) AtLeast(major, minor int) bool {
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", v.Major, v.Minor, v.Patch, v.Build)
+}
+
+// Color is a colour in the hex form the Stream Deck application uses, e.g. "#204cfe" or
+// "#204cfeff". It implements color.Color; malformed values are transparent black.
+type Color string
+
+// Parses the colour, accepting #rgb, #rrggbb and #rrggbbaa.
+func (c Color) Parse() (color.NRGBA, error) {
+	hex := strings.TrimPrefix(string(c), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("invalid colour %q", string(c))
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid colour %q", string(c))
+	}
+	return color.NRGBA{
+		R: uint8(value >> 24),
+		G: uint8(value >> 16),
+		B: uint8(value >> 8),
+		A: uint8(value),
+	}, nil
+}
+
+func (c Color) RGBA() (r, g, b, a uint32) {
+	parsed, _ := c.Parse()
+	return parsed.RGBA()
+}
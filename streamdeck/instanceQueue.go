@@ -0,0 +1,177 @@
+package streamdeck
+
+import "sync"
+
+// instanceQueue runs the work for a single action instance one task at a time, in the
+// order it was queued. It is unbounded so a slow handler never stalls the WebSocket reader.
+type instanceQueue struct {
+	mu     sync.Mutex
+	tasks  []func()
+	wake   chan struct{}
+	closed bool
+}
+
+var (
+	instanceQueues = make(map[string]*instanceQueue)
+	queueMutex     sync.Mutex
+)
+
+// startQueue runs a new queue's tasks. A replay swaps it to run them on its own goroutine.
+var startQueue = func(q *instanceQueue) { go q.run() }
+
+func newInstanceQueue() *instanceQueue {
+	q := &instanceQueue{wake: make(chan struct{}, 1)}
+	startQueue(q)
+	return q
+}
+
+func (q *instanceQueue) push(task func()) bool {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return false
+	}
+	q.tasks = append(q.tasks, task)
+	q.mu.Unlock()
+	q.signal()
+	return true
+}
+
+// close stops the queue once the tasks already queued have run.
+func (q *instanceQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *instanceQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *instanceQueue) run() {
+	for {
+		task, ok := q.pop()
+		if ok {
+			task()
+			continue
+		}
+		if q.finished() {
+			return
+		}
+		<-q.wake
+	}
+}
+
+// pop removes the next task, reporting false when there is none.
+func (q *instanceQueue) pop() (func(), bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.tasks) == 0 {
+		return nil, false
+	}
+	task := q.tasks[0]
+	q.tasks = q.tasks[1:]
+	return task, true
+}
+
+// finished reports whether the queue is closed and every task has been taken.
+func (q *instanceQueue) finished() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed && len(q.tasks) == 0
+}
+
+// drain runs the queued tasks on the calling goroutine, for queues started without one.
+// It reports whether any task ran.
+func (q *instanceQueue) drain() bool {
+	ran := false
+	for {
+		task, ok := q.pop()
+		if !ok {
+			return ran
+		}
+		task()
+		ran = true
+	}
+}
+
+func openInstanceQueue(context string) {
+	queueMutex.Lock()
+	defer queueMutex.Unlock()
+
+	if _, ok := instanceQueues[context]; !ok {
+		instanceQueues[context] = newInstanceQueue()
+	}
+}
+
+func closeInstanceQueue(context string) {
+	queueMutex.Lock()
+	q, ok := instanceQueues[context]
+	delete(instanceQueues, context)
+	queueMutex.Unlock()
+
+	if ok {
+		q.close()
+	}
+	stopInstanceTimers(context)
+	forgetGestures(context)
+	forgetLayout(context)
+}
+
+// resetInstanceQueues closes the queue of every instance.
+func resetInstanceQueues() {
+	queueMutex.Lock()
+	contexts := make([]string, 0, len(instanceQueues))
+	for context := range instanceQueues {
+		contexts = append(contexts, context)
+	}
+	queueMutex.Unlock()
+
+	for _, context := range contexts {
+		closeInstanceQueue(context)
+	}
+}
+
+// enqueue runs task on the queue of the given instance. It reports false when the
+// instance is not visible.
+func enqueue(context string, task func()) bool {
+	queueMutex.Lock()
+	q, ok := instanceQueues[context]
+	queueMutex.Unlock()
+
+	return ok && q.push(task)
+}
+
+// scheduleDispatch dispatches action events on their instance's queue, so handlers for
+// the same key never run concurrently. Everything else is dispatched on its own goroutine.
+func scheduleDispatch(event StreamDeckEvent) {
+	scheduleDispatchOr(event, func(event StreamDeckEvent) { go DispatchEvent(event) })
+}
+
+// scheduleDispatchOr is scheduleDispatch with the dispatch used for events that don't
+// belong to a visible instance.
+func scheduleDispatchOr(event StreamDeckEvent, dispatch func(StreamDeckEvent)) {
+	contextEvent, ok := event.(interface{ GetContext() string })
+	if !ok {
+		dispatch(event)
+		return
+	}
+	context := contextEvent.GetContext()
+
+	if _, ok := event.(*WillAppearEvent); ok {
+		openInstanceQueue(context)
+	}
+
+	if !enqueue(context, func() { DispatchEvent(event) }) {
+		dispatch(event)
+	}
+
+	if _, ok := event.(*WillDisappearEvent); ok {
+		closeInstanceQueue(context)
+	}
+}
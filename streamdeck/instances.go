@@ -0,0 +1,177 @@
+package streamdeck
+
+import (
+	"sort"
+	"sync"
+)
+
+// Instance is a snapshot of an action instance that is currently visible on a device.
+// It embeds the instance's ActionRef, so every command method (SetTitle, SetImage,
+// ShowOk, ...) can be called on it directly.
+type Instance struct {
+	ActionRef
+	Coordinates     ActionCoordinates
+	Controller      string
+	State           int
+	Settings        ActionSettings
+	IsInMultiAction bool
+}
+
+var (
+	instanceRegistry = make(map[string]*Instance)
+	instanceMutex    sync.RWMutex
+)
+
+// Returns every visible instance of the action with the given UUID, ordered by device
+// and position.
+//
+// Usage:
+//
+//	for _, inst := range streamdeck.Instances(a.UUID) {
+//		log.Printf("%s is at %+v", inst.Context, inst.Coordinates)
+//	}
+func Instances(actionUUID string) []Instance {
+	instanceMutex.RLock()
+	defer instanceMutex.RUnlock()
+
+	var instances []Instance
+	for _, inst := range instanceRegistry {
+		if inst.Action == actionUUID {
+			instances = append(instances, inst.snapshot())
+		}
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		a, b := instances[i], instances[j]
+		if a.Device != b.Device {
+			return a.Device < b.Device
+		}
+		if a.Coordinates.Row != b.Coordinates.Row {
+			return a.Coordinates.Row < b.Coordinates.Row
+		}
+		if a.Coordinates.Column != b.Coordinates.Column {
+			return a.Coordinates.Column < b.Coordinates.Column
+		}
+		return a.Context < b.Context
+	})
+	return instances
+}
+
+// Returns the visible instance with the given context.
+//
+// Usage:
+//
+//	inst, ok := streamdeck.GetInstance(context)
+func GetInstance(context string) (Instance, bool) {
+	instanceMutex.RLock()
+	defer instanceMutex.RUnlock()
+
+	inst, ok := instanceRegistry[context]
+	if !ok {
+		return Instance{}, false
+	}
+	return inst.snapshot(), true
+}
+
+// Returns a handle for sending commands to the visible instance with the given context,
+// e.g. from a webhook listener or a background goroutine.
+//
+// Usage:
+//
+//	if ref, ok := streamdeck.GetActionRef(context); ok {
+//		ref.SetTitle("Build passed")
+//	}
+func GetActionRef(context string) (*ActionRef, bool) {
+	instanceMutex.RLock()
+	defer instanceMutex.RUnlock()
+
+	inst, ok := instanceRegistry[context]
+	if !ok {
+		return nil, false
+	}
+	ref := inst.ActionRef
+	return &ref, true
+}
+
+// Calls fn for every visible instance of the action with the given UUID. Useful for
+// broadcasting an update to every key showing an action.
+//
+// Usage:
+//
+//	counter++
+//	streamdeck.ForEachInstance(a.UUID, func(inst streamdeck.Instance) {
+//		inst.SetTitle(strconv.FormatUint(uint64(counter), 10))
+//	})
+func ForEachInstance(actionUUID string, fn func(inst Instance)) {
+	for _, inst := range Instances(actionUUID) {
+		fn(inst)
+	}
+}
+
+func (i *Instance) snapshot() Instance {
+	inst := *i
+	if i.Settings != nil {
+		inst.Settings = make(ActionSettings, len(i.Settings))
+		for k, v := range i.Settings {
+			inst.Settings[k] = v
+		}
+	}
+	return inst
+}
+
+// trackInstance keeps the registry in step with the events describing action instances.
+func trackInstance(event StreamDeckEvent) {
+	instanceMutex.Lock()
+	defer instanceMutex.Unlock()
+
+	switch e := event.(type) {
+	case *WillAppearEvent:
+		instanceRegistry[e.Context] = &Instance{
+			ActionRef:       e.ActionRef,
+			Coordinates:     e.Payload.Coordinates,
+			Controller:      e.Payload.Controller,
+			State:           e.Payload.State,
+			Settings:        e.Payload.Settings,
+			IsInMultiAction: e.Payload.IsInMultiAction,
+		}
+	case *WillDisappearEvent:
+		delete(instanceRegistry, e.Context)
+	case *DidReceiveSettingsEvent:
+		if inst, ok := instanceRegistry[e.Context]; ok {
+			inst.Settings = e.Payload.Settings
+			inst.Coordinates = e.Payload.Coordinates
+			inst.IsInMultiAction = e.Payload.IsInMultiAction
+		}
+	case *KeyDownEvent:
+		if inst, ok := instanceRegistry[e.Context]; ok {
+			inst.Settings = e.Payload.Settings
+			inst.State = e.Payload.State
+		}
+	case *KeyUpEvent:
+		if inst, ok := instanceRegistry[e.Context]; ok {
+			inst.Settings = e.Payload.Settings
+			inst.State = e.Payload.State
+		}
+	case *TitleParametersDidChangeEvent:
+		if inst, ok := instanceRegistry[e.Context]; ok {
+			inst.Settings = e.Payload.Settings
+			inst.State = e.Payload.State
+		}
+	}
+}
+
+// updateInstanceSettings records settings the plugin itself saved, as the Stream Deck
+// does not echo them back with didReceiveSettings.
+func updateInstanceSettings(context string, settings ActionSettings) {
+	instanceMutex.Lock()
+	defer instanceMutex.Unlock()
+
+	if inst, ok := instanceRegistry[context]; ok {
+		inst.Settings = settings
+	}
+}
+
+func resetInstances() {
+	instanceMutex.Lock()
+	instanceRegistry = make(map[string]*Instance)
+	instanceMutex.Unlock()
+}
@@ -0,0 +1,120 @@
+package streamdeck
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrMissingLaunchArgs is returned by ParseLaunchArgs when a required argument was
+// neither passed nor set in the environment.
+var ErrMissingLaunchArgs = errors.New("missing launch arguments")
+
+// LaunchArgs are the arguments the Stream Deck application starts the plugin with.
+//
+// Docs:
+// https://docs.elgato.com/streamdeck/sdk/references/websocket/plugin/#registration-procedure
+type LaunchArgs struct {
+	Port          string
+	PluginUUID    string
+	RegisterEvent string
+	Info          Info
+}
+
+// launchFlags maps each launch argument to the environment variable read when it's missing.
+var launchFlags = []struct {
+	name, env string
+	required  bool
+}{
+	{"port", "STREAMDECK_PORT", true},
+	{"pluginUUID", "STREAMDECK_PLUGIN_UUID", true},
+	{"registerEvent", "STREAMDECK_REGISTER_EVENT", true},
+	{"info", "STREAMDECK_INFO", false},
+}
+
+// Parses the launch arguments, usually os.Args[1:], without touching flag.CommandLine.
+// Arguments the Stream Deck doesn't pass are ignored, and missing arguments are read from
+// the STREAMDECK_PORT, STREAMDECK_PLUGIN_UUID, STREAMDECK_REGISTER_EVENT and STREAMDECK_INFO
+// environment variables, so the plugin can be run by hand while developing.
+//
+// Usage:
+//
+//	args, err := streamdeck.ParseLaunchArgs(os.Args[1:])
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	streamdeck.StartPluginWithArgs(args)
+func ParseLaunchArgs(args []string) (LaunchArgs, error) {
+	fs := flag.NewFlagSet("streamdeck", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	values := make(map[string]*string, len(launchFlags))
+	for _, f := range launchFlags {
+		values[f.name] = fs.String(f.name, "", "")
+	}
+	if err := fs.Parse(knownLaunchArgs(args)); err != nil {
+		return LaunchArgs{}, fmt.Errorf("parsing launch arguments: %w", err)
+	}
+
+	var missing []string
+	for _, f := range launchFlags {
+		if *values[f.name] == "" {
+			*values[f.name] = os.Getenv(f.env)
+		}
+		if f.required && *values[f.name] == "" {
+			missing = append(missing, fmt.Sprintf("-%s (or %s)", f.name, f.env))
+		}
+	}
+	if len(missing) > 0 {
+		return LaunchArgs{}, fmt.Errorf("%w: %s", ErrMissingLaunchArgs, strings.Join(missing, ", "))
+	}
+
+	launchArgs := LaunchArgs{
+		Port:          *values["port"],
+		PluginUUID:    *values["pluginUUID"],
+		RegisterEvent: *values["registerEvent"],
+	}
+	if info := *values["info"]; info != "" {
+		if err := json.Unmarshal([]byte(info), &launchArgs.Info); err != nil {
+			return LaunchArgs{}, fmt.Errorf("parsing -info: %w", err)
+		}
+	}
+	return launchArgs, nil
+}
+
+// knownLaunchArgs drops every argument that isn't a launch flag, along with its value.
+func knownLaunchArgs(args []string) []string {
+	known := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			continue
+		}
+		if arg == "--" {
+			break
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !isLaunchFlag(name) {
+			continue
+		}
+		known = append(known, arg)
+		if !hasValue && i+1 < len(args) {
+			i++
+			known = append(known, args[i])
+		}
+	}
+	return known
+}
+
+func isLaunchFlag(name string) bool {
+	for _, f := range launchFlags {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package streamdeck
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKnownLaunchArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "stream deck arguments",
+			args: []string{"-port", "28196", "-pluginUUID", "abc", "-registerEvent", "registerPlugin", "-info", "{}"},
+			want: []string{"-port", "28196", "-pluginUUID", "abc", "-registerEvent", "registerPlugin", "-info", "{}"},
+		},
+		{
+			name: "unknown flag and its value are dropped",
+			args: []string{"-verbose", "2", "-port", "28196"},
+			want: []string{"-port", "28196"},
+		},
+		{
+			name: "unknown boolean flag before a known one",
+			args: []string{"-debug", "-port", "28196"},
+			want: []string{"-port", "28196"},
+		},
+		{
+			name: "double dashes and inline values",
+			args: []string{"--port=28196", "--pluginUUID", "abc"},
+			want: []string{"--port=28196", "--pluginUUID", "abc"},
+		},
+		{
+			name: "positional arguments are dropped",
+			args: []string{"serve", "-port", "28196", "extra"},
+			want: []string{"-port", "28196"},
+		},
+		{
+			name: "nothing after a terminator",
+			args: []string{"-port", "28196", "--", "-pluginUUID", "abc"},
+			want: []string{"-port", "28196"},
+		},
+		{
+			name: "known flag without a value",
+			args: []string{"-port"},
+			want: []string{"-port"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := knownLaunchArgs(tt.args)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLaunchArgs(t *testing.T) {
+	full := []string{
+		"-port", "28196",
+		"-pluginUUID", "abc",
+		"-registerEvent", "registerPlugin",
+		"-info", `{"application":{"language":"de","platform":"mac","version":"6.9.0"}}`,
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		want    LaunchArgs
+		wantErr string
+	}{
+		{
+			name: "all arguments",
+			args: full,
+			want: LaunchArgs{Port: "28196", PluginUUID: "abc", RegisterEvent: "registerPlugin"},
+		},
+		{
+			name: "unknown arguments are ignored",
+			args: append([]string{"-test.v", "-config", "dev.json"}, full...),
+			want: LaunchArgs{Port: "28196", PluginUUID: "abc", RegisterEvent: "registerPlugin"},
+		},
+		{
+			name: "environment fills missing arguments",
+			args: []string{"-port", "28196"},
+			env:  map[string]string{"STREAMDECK_PLUGIN_UUID": "env-uuid", "STREAMDECK_REGISTER_EVENT": "registerPlugin"},
+			want: LaunchArgs{Port: "28196", PluginUUID: "env-uuid", RegisterEvent: "registerPlugin"},
+		},
+		{
+			name: "arguments win over the environment",
+			args: full,
+			env:  map[string]string{"STREAMDECK_PORT": "1"},
+			want: LaunchArgs{Port: "28196", PluginUUID: "abc", RegisterEvent: "registerPlugin"},
+		},
+		{
+			name: "info is optional",
+			args: []string{"-port", "1", "-pluginUUID", "abc", "-registerEvent", "registerPlugin"},
+			want: LaunchArgs{Port: "1", PluginUUID: "abc", RegisterEvent: "registerPlugin"},
+		},
+		{
+			name:    "missing arguments are all listed",
+			args:    []string{"-port", "28196"},
+			wantErr: "missing launch arguments: -pluginUUID (or STREAMDECK_PLUGIN_UUID), -registerEvent (or STREAMDECK_REGISTER_EVENT)",
+		},
+		{
+			name:    "malformed info",
+			args:    []string{"-port", "1", "-pluginUUID", "abc", "-registerEvent", "registerPlugin", "-info", "{"},
+			wantErr: "parsing -info",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, f := range launchFlags {
+				t.Setenv(f.env, tt.env[f.env])
+			}
+
+			got, err := ParseLaunchArgs(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Info decoding has its own rules, so only the plain arguments are compared.
+			info := got.Info
+			got.Info = Info{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if strings.Contains(strings.Join(tt.args, " "), "-info") && info.Application.Language != "de" {
+				t.Errorf("got info %+v, want it parsed from -info", info)
+			}
+		})
+	}
+}
+
+func TestParseLaunchArgsMissingError(t *testing.T) {
+	for _, f := range launchFlags {
+		t.Setenv(f.env, "")
+	}
+	_, err := ParseLaunchArgs(nil)
+	if !errors.Is(err, ErrMissingLaunchArgs) {
+		t.Errorf("got %v, want ErrMissingLaunchArgs", err)
+	}
+}
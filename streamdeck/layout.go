@@ -0,0 +1,270 @@
+package streamdeck
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	LayoutWidth  = 200 // Width of the touch strip canvas of a single dial.
+	LayoutHeight = 100 // Height of the touch strip canvas of a single dial.
+
+	layoutSchema = "https://schemas.elgato.com/streamdeck/plugins/layout.json"
+)
+
+// Rect is the position and size of a layout item: x, y, width and height.
+type Rect [4]int
+
+func (r Rect) contains(x, y int) bool {
+	return x >= r[0] && x < r[0]+r[2] && y >= r[1] && y < r[1]+r[3]
+}
+
+// Layout describes a custom touch strip layout for the Stream Deck +.
+//
+// Usage:
+//
+//	var VolumeLayout = &streamdeck.Layout{
+//		ID:   "volume",
+//		Path: "layouts/volume.json",
+//		Items: []streamdeck.LayoutItem{
+//			&streamdeck.TextItem{ItemBase: streamdeck.ItemBase{Key: "title", Rect: streamdeck.Rect{16, 10, 168, 24}}},
+//			&streamdeck.BarItem{ItemBase: streamdeck.ItemBase{Key: "indicator", Rect: streamdeck.Rect{16, 60, 168, 20}}},
+//		},
+//	}
+//
+// Docs:
+// https://docs.elgato.com/streamdeck/sdk/guides/dials#layouts
+type Layout struct {
+	ID    string
+	Path  string // Path of the layout file, relative to the plugin bundle.
+	Items []LayoutItem
+}
+
+// LayoutItem is one of TextItem, PixmapItem, BarItem or GBarItem.
+type LayoutItem interface {
+	base() *ItemBase
+	itemType() string
+}
+
+// ItemBase holds the properties shared by every layout item.
+type ItemBase struct {
+	Key        string   `json:"key"`
+	Rect       Rect     `json:"rect"`
+	ZOrder     int      `json:"zOrder,omitempty"`
+	Enabled    *bool    `json:"enabled,omitempty"`
+	Opacity    *float64 `json:"opacity,omitempty"`
+	Background string   `json:"background,omitempty"`
+}
+
+type LayoutFont struct {
+	Size   int `json:"size,omitempty"`
+	Weight int `json:"weight,omitempty"`
+}
+
+type TextItem struct {
+	ItemBase
+	Value        string      `json:"value,omitempty"`
+	Font         *LayoutFont `json:"font,omitempty"`
+	Alignment    string      `json:"alignment,omitempty"` // "left", "center" or "right"
+	Color        string      `json:"color,omitempty"`
+	TextOverflow string      `json:"text-overflow,omitempty"` // "clip", "ellipsis" or "fade"
+}
+
+type PixmapItem struct {
+	ItemBase
+	Value string `json:"value,omitempty"` // Image path or data URL.
+}
+
+type BarRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+type BarItem struct {
+	ItemBase
+	Value      int       `json:"value"`
+	Subtype    int       `json:"subtype,omitempty"`
+	BarBgC     string    `json:"bar_bg_c,omitempty"`
+	BarFillC   string    `json:"bar_fill_c,omitempty"`
+	BarBorderC string    `json:"bar_border_c,omitempty"`
+	BorderW    int       `json:"border_w,omitempty"`
+	Range      *BarRange `json:"range,omitempty"`
+}
+
+type GBarItem struct {
+	BarItem
+	BarH int `json:"bar_h,omitempty"`
+}
+
+func (i *ItemBase) base() *ItemBase { return i }
+
+func (i *TextItem) itemType() string   { return "text" }
+func (i *PixmapItem) itemType() string { return "pixmap" }
+func (i *BarItem) itemType() string    { return "bar" }
+func (i *GBarItem) itemType() string   { return "gbar" }
+
+// Checks that every item has a unique key and fits on the 200x100 canvas.
+func (l *Layout) Validate() error {
+	if l.ID == "" {
+		return fmt.Errorf("layout has no ID")
+	}
+	keys := make(map[string]bool)
+	for _, item := range l.Items {
+		b := item.base()
+		if b.Key == "" {
+			return fmt.Errorf("layout %s: %s item has no key", l.ID, item.itemType())
+		}
+		if keys[b.Key] {
+			return fmt.Errorf("layout %s: duplicate item key %s", l.ID, b.Key)
+		}
+		keys[b.Key] = true
+
+		r := b.Rect
+		if r[0] < 0 || r[1] < 0 || r[2] <= 0 || r[3] <= 0 || r[0]+r[2] > LayoutWidth || r[1]+r[3] > LayoutHeight {
+			return fmt.Errorf("layout %s: item %s rect %v is outside the %dx%d canvas", l.ID, b.Key, r, LayoutWidth, LayoutHeight)
+		}
+	}
+	return nil
+}
+
+func (l *Layout) item(key string) (LayoutItem, bool) {
+	for _, item := range l.Items {
+		if item.base().Key == key {
+			return item, true
+		}
+	}
+	return nil, false
+}
+
+func (l *Layout) MarshalJSON() ([]byte, error) {
+	items := make([]json.RawMessage, 0, len(l.Items))
+	for _, item := range l.Items {
+		data, err := marshalLayoutItem(item)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, data)
+	}
+	return json.Marshal(struct {
+		Schema string            `json:"$schema"`
+		ID     string            `json:"id"`
+		Items  []json.RawMessage `json:"items"`
+	}{
+		Schema: layoutSchema,
+		ID:     l.ID,
+		Items:  items,
+	})
+}
+
+// marshalLayoutItem encodes the item's properties and adds its "type".
+func marshalLayoutItem(item LayoutItem) ([]byte, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["type"] = item.itemType()
+	return json.Marshal(fields)
+}
+
+// Validates the layout and writes it to Path inside the plugin bundle directory.
+//
+// Usage:
+//
+//	err := VolumeLayout.WriteFile("com.example.volume.sdPlugin")
+func (l *Layout) WriteFile(bundleDir string) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+	if l.Path == "" {
+		return fmt.Errorf("layout %s has no path", l.ID)
+	}
+	data, err := json.MarshalIndent(l, "", "\t")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(bundleDir, l.Path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Feedback builds a setFeedback payload for a layout, checking every key against the
+// items the layout declares.
+type Feedback struct {
+	layout  *Layout
+	payload map[string]any
+	err     error
+}
+
+// Starts a setFeedback payload for the layout.
+//
+// Usage:
+//
+//	payload, err := VolumeLayout.Feedback().
+//		Text("title", "Volume").
+//		Bar("indicator", 42).
+//		Payload()
+//	if err == nil {
+//		e.SetFeedback(payload)
+//	}
+func (l *Layout) Feedback() *Feedback {
+	return &Feedback{layout: l, payload: make(map[string]any)}
+}
+
+// Sets the value of a text item.
+func (f *Feedback) Text(key, value string) *Feedback {
+	return f.set(key, value, "text")
+}
+
+// Sets the value of a pixmap item to an image path or data URL.
+func (f *Feedback) Pixmap(key, value string) *Feedback {
+	return f.set(key, value, "pixmap")
+}
+
+// Sets the value of a bar or gbar item.
+func (f *Feedback) Bar(key string, value int) *Feedback {
+	return f.set(key, value, "bar", "gbar")
+}
+
+// Sets arbitrary properties of an item, e.g. its color or opacity.
+func (f *Feedback) Item(key string, properties map[string]any) *Feedback {
+	return f.set(key, properties)
+}
+
+func (f *Feedback) set(key string, value any, types ...string) *Feedback {
+	if f.err != nil {
+		return f
+	}
+	item, ok := f.layout.item(key)
+	if !ok {
+		f.err = fmt.Errorf("layout %s has no item %s", f.layout.ID, key)
+		return f
+	}
+	if len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			matched = matched || item.itemType() == t
+		}
+		if !matched {
+			f.err = fmt.Errorf("layout %s: item %s is a %s item", f.layout.ID, key, item.itemType())
+			return f
+		}
+	}
+	f.payload[key] = value
+	return f
+}
+
+// Returns the payload for SetFeedback, or the first error encountered while building it.
+func (f *Feedback) Payload() (map[string]any, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.payload, nil
+}
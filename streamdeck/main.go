@@ -1,19 +1,38 @@
 package streamdeck
 
 import (
-	"encoding/json"
-	"flag"
 	"log"
 	"net/url"
 	"os"
 	"os/signal"
+	"sync/atomic"
 
 	"github.com/gorilla/websocket"
 )
 
 var actionRegistry = make(map[string]Action)
 
+// pluginRunning guards against the plugin being started while it's already running.
+var pluginRunning atomic.Bool
+
+// Starts the plugin with the arguments it was launched with, and runs until interrupted.
 func StartPlugin() {
+	args, err := ParseLaunchArgs(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Error parsing launch arguments: %v", err)
+	}
+	StartPluginWithArgs(args)
+}
+
+// Starts the plugin with launch arguments parsed by ParseLaunchArgs, and runs until
+// interrupted. Calls made while the plugin is already running return straight away.
+func StartPluginWithArgs(args LaunchArgs) {
+	if !pluginRunning.CompareAndSwap(false, true) {
+		log.Println("StartPlugin called while the plugin is already running")
+		return
+	}
+	defer pluginRunning.Store(false)
+
 	// Open log file
 	logFile, err := os.OpenFile("streamdeck.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
@@ -25,33 +44,22 @@ func StartPlugin() {
 
 	defer logFile.Close()
 
-	// Define flags
-	port := flag.String("port", "", "WebSocket port")
-	pluginUUID := flag.String("pluginUUID", "", "Plugin UUID")
-	registerEvent := flag.String("registerEvent", "", "Event to register")
-	info := flag.String("info", "", "Stream Deck information")
-
-	flag.Parse()
-
-	// Parse the info JSON
-	var sdInfo StreamDeckInfo
-	if err := json.Unmarshal([]byte(*info), &sdInfo); err != nil {
-		log.Fatalf("Error parsing info JSON: %v", err)
-	}
-
 	PluginConfig = PluginConfigType{
-		Port:          *port,
-		PluginUUID:    *pluginUUID,
-		RegisterEvent: *registerEvent,
-		Info:          sdInfo,
+		Port:          args.Port,
+		PluginUUID:    args.PluginUUID,
+		RegisterEvent: args.RegisterEvent,
+		Info:          args.Info,
 	}
 
 	log.Printf("%+v", PluginConfig)
 
+	seedDevices(args.Info)
+
 	// OpenWebsocketAndRegisterPlugin()
 
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
 
 	// RegisterAction(CounterAction)
 
@@ -65,6 +73,7 @@ func StartPlugin() {
 	defer c.Close()
 
 	WsClient = c
+	markConnected()
 
 	registerMessage := map[string]string{
 		"event": PluginConfig.RegisterEvent,
@@ -75,15 +84,21 @@ func StartPlugin() {
 		log.Fatalf("Error sending register message: %v", err)
 	}
 
+	if err := requestGlobalSettings(); err != nil {
+		log.Printf("Error requesting global settings: %v", err)
+	}
+
 	// Listen for messages from WebSocket
 	go func() {
 		for {
 			_, message, err := WsClient.ReadMessage()
 			if err != nil {
 				log.Println("read:", err)
+				markDisconnected()
 				return
 			}
 
+			recordFrame(FrameIn, message)
 			HandleEvent(message)
 		}
 	}()
@@ -92,7 +107,10 @@ func StartPlugin() {
 	for {
 		<-interrupt
 		log.Println("Interrupt received, shutting down...")
+		stopAllTimers()
+		writeMutex.Lock()
 		WsClient.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		writeMutex.Unlock()
 		return
 	}
 
@@ -0,0 +1,174 @@
+package streamdeck
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Writes what the plugin declares in Go into the manifest.json inside the plugin bundle
+// directory: the profile catalogue under Profiles, and the applications registered with
+// OnApplication under ApplicationsToMonitor. Empty ones are removed, and other manifest
+// fields are left alone.
+//
+// Usage:
+//
+//	err := streamdeck.Plugin.WriteManifest("com.example.mixer.sdPlugin")
+func (p *PluginHandle) WriteManifest(bundleDir string) error {
+	var profiles, applications any
+	if declared := p.profiles.Manifest(); len(declared) > 0 {
+		profiles = declared
+	}
+	if monitored := MonitoredApplications(); len(monitored) > 0 {
+		applications = monitored
+	}
+	return updateManifest(bundleDir, map[string]any{
+		"Profiles":              profiles,
+		"ApplicationsToMonitor": applications,
+	})
+}
+
+// manifestField is a top-level field of manifest.json, with its value as written.
+type manifestField struct {
+	key   string
+	value json.RawMessage
+}
+
+// updateManifest replaces the given top-level fields of manifest.json, removing those
+// whose value is nil. The other fields are written back as they were, in the same order,
+// and new ones are added at the end.
+func updateManifest(bundleDir string, fields map[string]any) error {
+	path := filepath.Join(bundleDir, "manifest.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	manifest, err := decodeManifest(data)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	indent := manifestIndent(data)
+
+	var updated []manifestField
+	seen := make(map[string]bool)
+	for _, field := range manifest {
+		value, ok := fields[field.key]
+		if !ok {
+			updated = append(updated, field)
+			continue
+		}
+		seen[field.key] = true
+		if value == nil {
+			continue
+		}
+		encoded, err := encodeManifestValue(value, indent)
+		if err != nil {
+			return err
+		}
+		updated = append(updated, manifestField{field.key, encoded})
+	}
+
+	// Sorted, so the manifest doesn't change from one run to the next.
+	keys := make([]string, 0, len(fields))
+	for key, value := range fields {
+		if !seen[key] && value != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		encoded, err := encodeManifestValue(fields[key], indent)
+		if err != nil {
+			return err
+		}
+		updated = append(updated, manifestField{key, encoded})
+	}
+
+	var out bytes.Buffer
+	out.WriteString("{\n")
+	for i, field := range updated {
+		key, err := encodeManifestValue(field.key, indent)
+		if err != nil {
+			return err
+		}
+		out.WriteString(indent)
+		out.Write(key)
+		out.WriteString(": ")
+		out.Write(field.value)
+		if i < len(updated)-1 {
+			out.WriteByte(',')
+		}
+		out.WriteByte('\n')
+	}
+	out.WriteString("}\n")
+	return os.WriteFile(path, out.Bytes(), 0644)
+}
+
+// decodeManifest splits manifest.json into its top-level fields, in the order they appear.
+func decodeManifest(data []byte) ([]manifestField, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, fmt.Errorf("manifest is not a JSON object")
+	}
+
+	var fields []manifestField
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, manifestField{key, value})
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// manifestIndent returns the indentation of the first field of manifest.json, so written
+// fields line up with the others. It defaults to a tab.
+func manifestIndent(data []byte) string {
+	start := bytes.IndexByte(data, '{')
+	if start < 0 {
+		return "\t"
+	}
+	rest := data[start+1:]
+	end := bytes.IndexByte(rest, '"')
+	if end < 0 {
+		return "\t"
+	}
+	space := rest[:end]
+	if newline := bytes.LastIndexByte(space, '\n'); newline >= 0 {
+		space = space[newline+1:]
+	}
+	if len(space) == 0 || len(bytes.Trim(space, " \t")) > 0 {
+		return "\t"
+	}
+	return string(space)
+}
+
+// encodeManifestValue encodes a value to sit at the top level of manifest.json. Unlike
+// json.Marshal, it leaves characters such as < and & unescaped, as they would be written
+// by hand.
+func encodeManifestValue(value any, indent string) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(indent, indent)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
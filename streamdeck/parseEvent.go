@@ -10,6 +10,7 @@ type eventParser func(data []byte) (StreamDeckEvent, error)
 var eventParsers = map[string]eventParser{
 	"didReceiveSettings":            parseDidReceiveSettings,
 	"didReceiveGlobalSettings":      parseDidReceiveGlobalSettings,
+	"didReceiveSecrets":             parseDidReceiveSecrets,
 	"didReceiveDeepLink":            parseDidReceiveDeepLink,
 	"touchTap":                      parseTouchTap,
 	"dialDown":                      parseDialDown,
@@ -41,6 +42,11 @@ func parseDidReceiveGlobalSettings(data []byte) (StreamDeckEvent, error) {
 	err := json.Unmarshal(data, &event)
 	return &event, err
 }
+func parseDidReceiveSecrets(data []byte) (StreamDeckEvent, error) {
+	var event DidReceiveSecretsEvent
+	err := json.Unmarshal(data, &event)
+	return &event, err
+}
 func parseDidReceiveDeepLink(data []byte) (StreamDeckEvent, error) {
 	var event DidReceiveDeepLinkEvent
 	err := json.Unmarshal(data, &event)
@@ -0,0 +1,131 @@
+package streamdeck
+
+// PluginHandle gives access to plugin-wide features that aren't tied to an action.
+type PluginHandle struct {
+	deepLinks *DeepLinkRouter
+	profiles  *ProfileCatalogue
+}
+
+// Plugin is the running plugin.
+var Plugin = &PluginHandle{
+	deepLinks: &DeepLinkRouter{},
+	profiles:  &ProfileCatalogue{},
+}
+
+// Returns the router deep links to the plugin are dispatched through.
+//
+// Usage:
+//
+//	streamdeck.Plugin.DeepLinks().Handle("/toggle/{id}", func(link streamdeck.DeepLink) {
+//		...
+//	})
+func (p *PluginHandle) DeepLinks() *DeepLinkRouter {
+	return p.deepLinks
+}
+
+// Returns the catalogue of profiles distributed with the plugin.
+//
+// Usage:
+//
+//	streamdeck.Plugin.Profiles().Register("mixer",
+//		streamdeck.ProfileVariant{Name: "profiles/mixer", DeviceType: streamdeck.DeviceStreamDeck},
+//	)
+func (p *PluginHandle) Profiles() *ProfileCatalogue {
+	return p.profiles
+}
+
+// Logs a message to the file-system.
+//
+// Usage:
+//
+//	streamdeck.Plugin.LogMessage("Device connected")
+//
+// Docs:
+// https://docs.elgato.com/sdk/plugins/events-sent#logmessage
+func (p *PluginHandle) LogMessage(message string) error {
+	response := LogMessageCommand{
+		Event: "logMessage",
+		Payload: struct {
+			Message string "json:\"message\""
+		}{
+			Message: message,
+		},
+	}
+	return SendEventToStreamDeck(response)
+}
+
+// Opens the URL in the user's default browser.
+//
+// Usage:
+//
+//	streamdeck.Plugin.OpenUrl("https://www.example.com")
+//
+// Docs:
+// https://docs.elgato.com/sdk/plugins/events-sent#openurl
+func (p *PluginHandle) OpenUrl(url string) error {
+	response := OpenUrlCommand{
+		Event: "openUrl",
+		Payload: struct {
+			Url string "json:\"url\""
+		}{
+			Url: url,
+		},
+	}
+	return SendEventToStreamDeck(response)
+}
+
+// Saves the plugin's global settings and updates the cache read by Global.
+//
+// Usage:
+//
+//	streamdeck.Plugin.SetGlobalSettings(map[string]any{
+//		"apikey": "mX8ulcBHYmMniSshmB59",
+//	})
+//
+// Docs:
+// https://docs.elgato.com/sdk/plugins/events-sent#setglobalsettings
+func (p *PluginHandle) SetGlobalSettings(settings map[string]any) error {
+	response := SetGlobalSettingsCommand{
+		Event:   "setGlobalSettings",
+		Context: PluginConfig.PluginUUID,
+		Payload: settings,
+	}
+	if err := SendEventToStreamDeck(response); err != nil {
+		return err
+	}
+	storeGlobalSettings(settings)
+	return nil
+}
+
+// Switches to the profile, as distributed by the plugin, on the given device.
+//
+// NB: Plugins may only switch to profiles distributed with the plugin, as defined
+// within the manifest, and cannot access user-defined profiles.
+//
+// Usage:
+//
+//	streamdeck.Plugin.SwitchToProfile(event.Device, "MyPluginProfile")
+//	streamdeck.Plugin.SwitchToProfile(event.Device, "MyPluginProfile", 1)
+//
+// Docs:
+// https://docs.elgato.com/sdk/plugins/events-sent#switchtoprofile
+func (p *PluginHandle) SwitchToProfile(device, profile string, page ...uint8) error {
+	var pageIndex uint8 = 0
+
+	if len(page) > 0 {
+		pageIndex = page[0]
+	}
+	response := SwitchToProfileCommand{
+		Event:   "switchToProfile",
+		Context: PluginConfig.PluginUUID,
+		Device:  device,
+		Payload: struct {
+			Profile string "json:\"profile\""
+			Page    uint8  "json:\"page\""
+		}{
+			Profile: profile,
+			Page:    pageIndex,
+		},
+	}
+	return SendEventToStreamDeck(response)
+}
@@ -0,0 +1,142 @@
+package streamdeck
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrUnknownDevice is returned when switching profiles on a device the plugin hasn't
+	// been told about.
+	ErrUnknownDevice = errors.New("unknown device")
+	// ErrUnknownProfile is returned when switching to a profile missing from the catalogue,
+	// or one without a variant for the device type.
+	ErrUnknownProfile = errors.New("unknown profile")
+)
+
+// ProfileVariant is a profile distributed with the plugin for one type of device.
+type ProfileVariant struct {
+	Name                        string     // Path of the profile in the bundle, without the .streamDeckProfile extension.
+	DeviceType                  DeviceType // Type of device the profile was created for.
+	Readonly                    bool       // Stops the user editing the profile.
+	DontAutoSwitchWhenInstalled bool       // Stops the Stream Deck switching to the profile once installed.
+}
+
+// ManifestProfile is an entry of the Profiles array in manifest.json.
+//
+// Docs:
+// https://docs.elgato.com/streamdeck/sdk/references/manifest#profile
+type ManifestProfile struct {
+	Name                        string     `json:"Name"`
+	DeviceType                  DeviceType `json:"DeviceType"`
+	Readonly                    bool       `json:"Readonly"`
+	DontAutoSwitchWhenInstalled bool       `json:"DontAutoSwitchWhenInstalled"`
+}
+
+// ProfileCatalogue maps logical profile names to the variant shipped for each device type.
+type ProfileCatalogue struct {
+	mu       sync.RWMutex
+	profiles map[string]map[DeviceType]ProfileVariant
+}
+
+// Declares a logical profile and its variants, replacing any variants already declared
+// for the same device types.
+//
+// Usage:
+//
+//	streamdeck.Plugin.Profiles().Register("mixer",
+//		streamdeck.ProfileVariant{Name: "profiles/mixer", DeviceType: streamdeck.DeviceStreamDeck},
+//		streamdeck.ProfileVariant{Name: "profiles/mixer-xl", DeviceType: streamdeck.DeviceStreamDeckXL},
+//		streamdeck.ProfileVariant{Name: "profiles/mixer-plus", DeviceType: streamdeck.DeviceStreamDeckPlus, Readonly: true},
+//	)
+func (c *ProfileCatalogue) Register(logicalName string, variants ...ProfileVariant) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.profiles == nil {
+		c.profiles = make(map[string]map[DeviceType]ProfileVariant)
+	}
+	byType, ok := c.profiles[logicalName]
+	if !ok {
+		byType = make(map[DeviceType]ProfileVariant)
+		c.profiles[logicalName] = byType
+	}
+	for _, variant := range variants {
+		byType[variant.DeviceType] = variant
+	}
+}
+
+// Returns the variant of the logical profile for the device type.
+func (c *ProfileCatalogue) Lookup(logicalName string, deviceType DeviceType) (ProfileVariant, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	variant, ok := c.profiles[logicalName][deviceType]
+	return variant, ok
+}
+
+// Returns the catalogue as the Profiles array of manifest.json, ordered by name.
+func (c *ProfileCatalogue) Manifest() []ManifestProfile {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	profiles := []ManifestProfile{}
+	for _, byType := range c.profiles {
+		for _, variant := range byType {
+			profiles = append(profiles, ManifestProfile(variant))
+		}
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].Name != profiles[j].Name {
+			return profiles[i].Name < profiles[j].Name
+		}
+		return profiles[i].DeviceType < profiles[j].DeviceType
+	})
+	return profiles
+}
+
+// Replaces the Profiles array of the manifest.json inside the plugin bundle directory with
+// the catalogue, leaving the other manifest fields alone.
+//
+// Usage:
+//
+//	err := streamdeck.Plugin.Profiles().WriteManifest("com.example.mixer.sdPlugin")
+func (c *ProfileCatalogue) WriteManifest(bundleDir string) error {
+	return updateManifest(bundleDir, map[string]any{"Profiles": c.Manifest()})
+}
+
+// Switches the device to the variant of the logical profile made for its type. Page is
+// the zero-based page of the profile to show.
+//
+// Usage:
+//
+//	err := streamdeck.Plugin.SwitchToProfileFor(e.Device, "mixer", 0)
+//	if errors.Is(err, streamdeck.ErrUnknownProfile) {
+//		e.ShowAlert()
+//	}
+func (p *PluginHandle) SwitchToProfileFor(device, logicalName string, page int) error {
+	if page < 0 || page > 255 {
+		return invalidCommand("page %d is out of range 0-255", page)
+	}
+	d, ok := GetDevice(device)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownDevice, device)
+	}
+	variant, ok := p.profiles.Lookup(logicalName, d.Type)
+	if !ok {
+		return fmt.Errorf("%w: %s has no variant for %s", ErrUnknownProfile, logicalName, d.Type)
+	}
+	return p.SwitchToProfile(device, variant.Name, uint8(page))
+}
+
+// Switches the device of the action instance to the variant of the logical profile made
+// for its type.
+//
+// Usage:
+//
+//	e.SwitchToProfileFor("mixer", 0)
+func (r *ActionRef) SwitchToProfileFor(logicalName string, page int) error {
+	return Plugin.SwitchToProfileFor(r.Device, logicalName, page)
+}
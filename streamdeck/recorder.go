@@ -0,0 +1,58 @@
+package streamdeck
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	FrameIn  = "in"  // Stream Deck -> plugin
+	FrameOut = "out" // plugin -> Stream Deck
+)
+
+// SessionFrame is a single WebSocket frame captured by the session recorder.
+// A recorded session is stored as JSONL, one frame per line.
+type SessionFrame struct {
+	Direction string          `json:"direction"`
+	Data      json.RawMessage `json:"data"`
+	At        time.Duration   `json:"at,omitempty"` // Time since recording started, in nanoseconds.
+}
+
+var (
+	sessionRecorder *json.Encoder
+	recordingStart  time.Time
+	recorderMutex   sync.Mutex
+)
+
+// Records every frame sent to and received from the Stream Deck to w as JSONL.
+// The recording can later be fed to ReplaySession. Passing nil stops recording.
+//
+// Usage:
+//
+//	f, _ := os.Create("session.jsonl")
+//	streamdeck.RecordSession(f)
+func RecordSession(w io.Writer) {
+	recorderMutex.Lock()
+	defer recorderMutex.Unlock()
+	if w == nil {
+		sessionRecorder = nil
+		return
+	}
+	sessionRecorder = json.NewEncoder(w)
+	recordingStart = time.Now()
+}
+
+func recordFrame(direction string, data []byte) {
+	recorderMutex.Lock()
+	defer recorderMutex.Unlock()
+	if sessionRecorder == nil {
+		return
+	}
+	frame := SessionFrame{Direction: direction, Data: json.RawMessage(data), At: time.Since(recordingStart)}
+	if err := sessionRecorder.Encode(frame); err != nil {
+		log.Printf("Error recording frame: %v", err)
+	}
+}
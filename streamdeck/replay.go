@@ -0,0 +1,409 @@
+package streamdeck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ReplayMismatch describes a command that differs from the one in the recorded session.
+// Commands are compared per event and context, in the order they were sent, and aligned
+// so one extra or missing command doesn't throw off the rest. Expected is nil when the plugin sent more
+// commands than were recorded, and Actual is nil when it sent fewer.
+type ReplayMismatch struct {
+	Event    string
+	Context  string
+	Index    int // Position among the recorded commands with the same event and context, or the sent ones when extra.
+	Expected json.RawMessage
+	Actual   json.RawMessage
+}
+
+func (m ReplayMismatch) String() string {
+	return fmt.Sprintf("%s %s #%d: expected %s, got %s", m.Event, m.Context, m.Index, m.Expected, m.Actual)
+}
+
+var replayMutex sync.Mutex
+
+// Replays a session recorded with RecordSession against the registered actions. Every
+// inbound frame goes through the same queues as a live event, and the replay waits for the
+// plugin to go idle before the next one. Timers and gestures follow a clock that moves to
+// the recorded time of each frame, so they fire between the same frames as they did,
+// without the replay taking as long as the session. The commands the actions send are
+// compared against the recorded outbound frames. Nothing is written to the WebSocket while replaying.
+//
+// Commands sent before the first inbound frame belong to the startup handshake and are
+// skipped. The SDK's registries are cleared before and after the replay, so sessions
+// don't leak into each other.
+//
+// Requests that wait for an answer, like GetSettings, are answered with the next matching
+// recorded response.
+//
+// Usage:
+//
+//	f, _ := os.Open("testdata/bug-report.jsonl")
+//	mismatches, err := streamdeck.ReplaySession(f)
+//	for _, m := range mismatches {
+//		t.Error(m)
+//	}
+func ReplaySession(r io.Reader) ([]ReplayMismatch, error) {
+	var (
+		inbound    []SessionFrame
+		expected   []json.RawMessage
+		end        time.Duration
+		sawInbound bool
+	)
+
+	decoder := json.NewDecoder(r)
+	for {
+		var frame SessionFrame
+		if err := decoder.Decode(&frame); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("error reading session: %w", err)
+		}
+		switch frame.Direction {
+		case FrameIn:
+			inbound = append(inbound, frame)
+			sawInbound = true
+		case FrameOut:
+			if !sawInbound {
+				continue
+			}
+			expected = append(expected, frame.Data)
+		default:
+			return nil, fmt.Errorf("unknown frame direction: %s", frame.Direction)
+		}
+		end = max(end, frame.At)
+	}
+	if len(inbound) == 0 {
+		return diffCommands(expected, nil), nil
+	}
+
+	replayMutex.Lock()
+	defer replayMutex.Unlock()
+
+	var (
+		actual []json.RawMessage
+		cursor int
+		queues []*instanceQueue
+	)
+
+	// Queues run on this goroutine and timers on a clock advanced frame by frame, so the
+	// replay plays out the same way every time, however long the session or busy the machine.
+	queueMutex.Lock()
+	originalStartQueue := startQueue
+	startQueue = func(q *instanceQueue) { queues = append(queues, q) }
+	queueMutex.Unlock()
+	sessionClock := &replayClock{now: time.Unix(0, 0)}
+	originalClock := setClock(sessionClock)
+	originalWriteFrame := writeFrame
+
+	settle := func() {
+		for busy := true; busy; {
+			busy = false
+			for _, q := range queues {
+				if q.drain() {
+					busy = true
+				}
+			}
+		}
+	}
+
+	resetSession()
+	defer func() {
+		resetSession()
+		settle()
+		writeFrame = originalWriteFrame
+		setClock(originalClock)
+		queueMutex.Lock()
+		startQueue = originalStartQueue
+		queueMutex.Unlock()
+		// Leave fresh queues that run on their own, like the ones replaced.
+		resetSession()
+	}()
+
+	remaining := make([]json.RawMessage, len(inbound))
+	for i, frame := range inbound {
+		remaining[i] = frame.Data
+	}
+
+	// Commands are captured where they would be written to the WebSocket, after being
+	// encoded exactly as they would be sent. Serving them through a WebSocket would add a
+	// reader goroutine the replay can't step, and nothing about the frames themselves.
+	writeFrame = func(data []byte) error {
+		actual = append(actual, append(json.RawMessage(nil), data...))
+		answerReplayRequest(data, remaining[cursor:])
+		return nil
+	}
+
+	start := sessionClock.Now()
+	base := inbound[0].At
+	for i, frame := range inbound {
+		sessionClock.advance(start.Add(frame.At-base), settle)
+		cursor = i + 1
+
+		event, err := ParseEvent(frame.Data)
+		if err != nil {
+			log.Printf("Error parsing event: %v", err)
+			continue
+		}
+		trackEvent(event)
+		routeResponse(event)
+		scheduleDispatchOr(event, DispatchEvent)
+		settle()
+	}
+
+	// Give timers started by the session the time they had when it was recorded.
+	sessionClock.advance(start.Add(end-base), settle)
+	resetSession()
+	settle()
+
+	return diffCommands(expected, actual), nil
+}
+
+// sessionResets clear the state each subsystem keeps about the connected Stream Deck.
+// Registered actions, deep link routes and subscriptions are kept.
+var sessionResets = []func(){
+	resetInstanceQueues,
+	stopAllTimers,
+	resetGestures,
+	resetLayouts,
+	resetInstances,
+	resetDevices,
+	resetGoneContexts,
+	resetRunningApplications,
+	resetGlobalSettings,
+	resetStatefulActions,
+}
+
+func resetSession() {
+	for _, reset := range sessionResets {
+		reset()
+	}
+}
+
+// replayClock is a clock that only moves when the replay advances it.
+type replayClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*replayTimer
+}
+
+type replayTimer struct {
+	clock   *replayClock
+	when    time.Time
+	f       func()
+	stopped bool
+}
+
+func (c *replayClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *replayClock) AfterFunc(d time.Duration, f func()) clockTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &replayTimer{clock: c, when: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (t *replayTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := !t.stopped
+	t.stopped = true
+	return active
+}
+
+// advance moves the clock to the given time, firing the timers due on the way in order,
+// and calling settle after each one so the work it queued runs before the next.
+func (c *replayClock) advance(to time.Time, settle func()) {
+	for {
+		c.mu.Lock()
+		next := -1
+		for i, t := range c.timers {
+			if t.stopped || t.when.After(to) {
+				continue
+			}
+			// Timers due at the same time fire in the order they were started.
+			if next < 0 || t.when.Before(c.timers[next].when) {
+				next = i
+			}
+		}
+		if next < 0 {
+			c.timers = slices.DeleteFunc(c.timers, func(t *replayTimer) bool { return t.stopped })
+			c.now = to
+			c.mu.Unlock()
+			return
+		}
+		t := c.timers[next]
+		c.timers = slices.Delete(c.timers, next, next+1)
+		t.stopped = true
+		c.now = t.when
+		c.mu.Unlock()
+
+		t.f()
+		settle()
+	}
+}
+
+// answerReplayRequest delivers the first recorded response to a command that expects one.
+func answerReplayRequest(command []byte, remaining []json.RawMessage) {
+	var request struct {
+		Event   string `json:"event"`
+		Context string `json:"context"`
+	}
+	if err := json.Unmarshal(command, &request); err != nil {
+		return
+	}
+
+	var want string
+	switch request.Event {
+	case "getSettings":
+		want = "didReceiveSettings"
+	case "getGlobalSettings":
+		want = "didReceiveGlobalSettings"
+	default:
+		return
+	}
+
+	for _, data := range remaining {
+		event, err := ParseEvent(data)
+		if err != nil || event.GetEventType() != want {
+			continue
+		}
+		if settingsEvent, ok := event.(*DidReceiveSettingsEvent); ok && settingsEvent.Context != request.Context {
+			continue
+		}
+		routeResponse(event)
+		return
+	}
+}
+
+type commandKey struct {
+	event, context string
+}
+
+func commandKeyOf(data json.RawMessage) commandKey {
+	var command struct {
+		Event   string `json:"event"`
+		Context string `json:"context"`
+	}
+	_ = json.Unmarshal(data, &command)
+	return commandKey{command.Event, command.Context}
+}
+
+// diffCommands pairs the commands sent for each event and context in order, and reports
+// the pairs that differ.
+func diffCommands(expected, actual []json.RawMessage) []ReplayMismatch {
+	var keys []commandKey
+	want := make(map[commandKey][]json.RawMessage)
+	got := make(map[commandKey][]json.RawMessage)
+	for _, data := range expected {
+		key := commandKeyOf(data)
+		if _, ok := want[key]; !ok {
+			keys = append(keys, key)
+		}
+		want[key] = append(want[key], data)
+	}
+	for _, data := range actual {
+		key := commandKeyOf(data)
+		if _, ok := want[key]; !ok {
+			if _, ok := got[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		got[key] = append(got[key], data)
+	}
+
+	var mismatches []ReplayMismatch
+	for _, key := range keys {
+		for _, m := range alignCommands(want[key], got[key]) {
+			m.Event, m.Context = key.event, key.context
+			mismatches = append(mismatches, m)
+		}
+	}
+	return mismatches
+}
+
+// alignCommands matches up the longest common subsequence of two command lists. Commands
+// left over between two matches are paired as changed, and the rest reported as missing
+// or extra.
+func alignCommands(expected, actual []json.RawMessage) []ReplayMismatch {
+	// common[i][j] is the length of the longest common subsequence of expected[i:] and actual[j:].
+	common := make([][]int, len(expected)+1)
+	for i := range common {
+		common[i] = make([]int, len(actual)+1)
+	}
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			if sameJSON(expected[i], actual[j]) {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var (
+		mismatches     []ReplayMismatch
+		missing, extra []int
+	)
+	flush := func() {
+		for n := 0; n < len(missing) || n < len(extra); n++ {
+			var m ReplayMismatch
+			if n < len(missing) {
+				m.Index = missing[n]
+				m.Expected = expected[missing[n]]
+			} else {
+				m.Index = extra[n]
+			}
+			if n < len(extra) {
+				m.Actual = actual[extra[n]]
+			}
+			mismatches = append(mismatches, m)
+		}
+		missing, extra = nil, nil
+	}
+
+	i, j := 0, 0
+	for i < len(expected) || j < len(actual) {
+		switch {
+		case i < len(expected) && j < len(actual) && sameJSON(expected[i], actual[j]):
+			flush()
+			i++
+			j++
+		case j == len(actual) || (i < len(expected) && common[i+1][j] >= common[i][j+1]):
+			missing = append(missing, i)
+			i++
+		default:
+			extra = append(extra, j)
+			j++
+		}
+	}
+	flush()
+	return mismatches
+}
+
+func sameJSON(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var x, y any
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,172 @@
+package streamdeck
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// counterAction sets its title to the number of presses, and to "ready" shortly after
+// it appears, so replays exercise the instance queue and timers.
+type counterAction struct {
+	ActionConfig
+	presses int
+}
+
+func (a *counterAction) HandleWillAppear(event *WillAppearEvent) {
+	a.presses = 0
+	event.SetTitle("0")
+	inst, _ := GetInstance(event.Context)
+	inst.After(20*time.Millisecond, func() {
+		inst.SetTitle("ready")
+	})
+}
+
+func (a *counterAction) HandleKeyUp(event *KeyUpEvent) {
+	a.presses++
+	event.SetTitle(strconv.Itoa(a.presses))
+}
+
+func registerCounterAction() {
+	RegisterAction(&counterAction{ActionConfig: ActionConfig{UUID: "com.example.counter"}})
+}
+
+func TestReplaySession(t *testing.T) {
+	registerCounterAction()
+
+	session, err := os.ReadFile("testdata/counter.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replaying twice checks that nothing from the first session leaks into the second.
+	for run := 0; run < 2; run++ {
+		mismatches, err := ReplaySession(bytes.NewReader(session))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range mismatches {
+			t.Errorf("run %d: %s", run, m)
+		}
+	}
+}
+
+func TestReplaySessionAlignsCommands(t *testing.T) {
+	registerCounterAction()
+
+	session, err := os.ReadFile("testdata/counter.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Drop the first recorded title, so the plugin sends one command more than expected.
+	var lines []string
+	dropped := false
+	for _, line := range strings.Split(strings.TrimSpace(string(session)), "\n") {
+		if !dropped && strings.Contains(line, `"setTitle"`) {
+			dropped = true
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	mismatches, err := ReplaySession(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 1 {
+		t.Fatalf("got %d mismatches, want the extra title only: %v", len(mismatches), mismatches)
+	}
+}
+
+func TestDiffCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+		actual   []string
+		want     int
+	}{
+		{
+			name:     "identical",
+			expected: []string{`{"event":"setTitle","context":"a","payload":{"title":"1"}}`},
+			actual:   []string{`{"event":"setTitle","context":"a","payload":{"title":"1"}}`},
+			want:     0,
+		},
+		{
+			name:     "key order doesn't matter",
+			expected: []string{`{"event":"setTitle","context":"a","payload":{"title":"1","target":0}}`},
+			actual:   []string{`{"context":"a","event":"setTitle","payload":{"target":0,"title":"1"}}`},
+			want:     0,
+		},
+		{
+			name: "interleaving across contexts doesn't matter",
+			expected: []string{
+				`{"event":"setTitle","context":"a","payload":{"title":"1"}}`,
+				`{"event":"setTitle","context":"b","payload":{"title":"2"}}`,
+			},
+			actual: []string{
+				`{"event":"setTitle","context":"b","payload":{"title":"2"}}`,
+				`{"event":"setTitle","context":"a","payload":{"title":"1"}}`,
+			},
+			want: 0,
+		},
+		{
+			name: "an extra command doesn't shift the others",
+			expected: []string{
+				`{"event":"setTitle","context":"a","payload":{"title":"1"}}`,
+				`{"event":"setImage","context":"a","payload":{"image":"x"}}`,
+			},
+			actual: []string{
+				`{"event":"logMessage","payload":{"message":"hi"}}`,
+				`{"event":"setTitle","context":"a","payload":{"title":"1"}}`,
+				`{"event":"setImage","context":"a","payload":{"image":"x"}}`,
+			},
+			want: 1,
+		},
+		{
+			name: "an extra command for the same context doesn't shift the later ones",
+			expected: []string{
+				`{"event":"setTitle","context":"a","payload":{"title":"1"}}`,
+				`{"event":"setTitle","context":"a","payload":{"title":"2"}}`,
+			},
+			actual: []string{
+				`{"event":"setTitle","context":"a","payload":{"title":"0"}}`,
+				`{"event":"setTitle","context":"a","payload":{"title":"1"}}`,
+				`{"event":"setTitle","context":"a","payload":{"title":"2"}}`,
+			},
+			want: 1,
+		},
+		{
+			name:     "a missing command",
+			expected: []string{`{"event":"showOk","context":"a"}`},
+			want:     1,
+		},
+		{
+			name:     "a different payload",
+			expected: []string{`{"event":"setTitle","context":"a","payload":{"title":"1"}}`},
+			actual:   []string{`{"event":"setTitle","context":"a","payload":{"title":"2"}}`},
+			want:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mismatches := diffCommands(rawMessages(tt.expected), rawMessages(tt.actual))
+			if len(mismatches) != tt.want {
+				t.Errorf("got %d mismatches, want %d: %v", len(mismatches), tt.want, mismatches)
+			}
+		})
+	}
+}
+
+func rawMessages(frames []string) []json.RawMessage {
+	messages := make([]json.RawMessage, len(frames))
+	for i, frame := range frames {
+		messages[i] = json.RawMessage(frame)
+	}
+	return messages
+}
@@ -1,50 +1,124 @@
 package streamdeck
 
 import (
+	"context"
+	"errors"
 	"sync"
+	"time"
 )
 
-type ResponseChannel chan StreamDeckEvent
+var (
+	// ErrTimeout is returned when the Stream Deck doesn't answer a request in time.
+	ErrTimeout = errors.New("timeout waiting for response from Stream Deck")
+	// ErrDisconnected is returned when the connection to the Stream Deck is lost while
+	// waiting for a response.
+	ErrDisconnected = errors.New("disconnected from Stream Deck")
+)
+
+// DefaultRequestTimeout applies to requests whose context has no deadline.
+var DefaultRequestTimeout = 5 * time.Second
+
+// pendingRequest is one caller waiting for a response. Several callers can wait on the
+// same key, and all of them receive the response.
+type pendingRequest struct {
+	ch chan StreamDeckEvent
+}
 
 var (
-	responseChannels = make(map[string]ResponseChannel)
-	responseMutex    sync.Mutex
+	pendingRequests = make(map[string]map[*pendingRequest]struct{})
+	pendingMutex    sync.Mutex
+
+	// disconnected is closed when the current connection drops, and replaced on connect.
+	disconnected     = make(chan struct{})
+	disconnectedOnce = new(sync.Once)
 )
 
-func registerResponseChannel(context string) ResponseChannel {
-	responseMutex.Lock()
-	defer responseMutex.Unlock()
-	ch := make(ResponseChannel, 1)
-	responseChannels[context] = ch
-	return ch
+func addPendingRequest(key string) *pendingRequest {
+	pendingMutex.Lock()
+	defer pendingMutex.Unlock()
+
+	req := &pendingRequest{ch: make(chan StreamDeckEvent, 1)}
+	if pendingRequests[key] == nil {
+		pendingRequests[key] = make(map[*pendingRequest]struct{})
+	}
+	pendingRequests[key][req] = struct{}{}
+	return req
 }
 
-func unregisterResponseChannel(context string) {
-	responseMutex.Lock()
-	defer responseMutex.Unlock()
-	delete(responseChannels, context)
+func removePendingRequest(key string, req *pendingRequest) {
+	pendingMutex.Lock()
+	defer pendingMutex.Unlock()
+
+	delete(pendingRequests[key], req)
+	if len(pendingRequests[key]) == 0 {
+		delete(pendingRequests, key)
+	}
 }
 
-func sendResponse(context string, event StreamDeckEvent) {
-	responseMutex.Lock()
-	ch, ok := responseChannels[context]
-	responseMutex.Unlock()
-	if ok {
+// sendResponse hands the event to every caller waiting on the key.
+func sendResponse(key string, event StreamDeckEvent) {
+	pendingMutex.Lock()
+	waiting := pendingRequests[key]
+	delete(pendingRequests, key)
+	pendingMutex.Unlock()
+
+	for req := range waiting {
 		select {
-		case ch <- event:
+		case req.ch <- event:
 		default:
 		}
 	}
 }
 
-// func waitForResponse(context string, timeout time.Duration) (StreamDeckEvent, error) {
-// 	ch := registerResponseChannel(context)
-// 	defer unregisterResponseChannel(context)
+// markConnected starts a new connection, whose requests wait for responses again.
+func markConnected() {
+	pendingMutex.Lock()
+	defer pendingMutex.Unlock()
+
+	disconnected = make(chan struct{})
+	disconnectedOnce = new(sync.Once)
+}
+
+// markDisconnected fails every request waiting on the current connection with ErrDisconnected.
+func markDisconnected() {
+	pendingMutex.Lock()
+	ch, once := disconnected, disconnectedOnce
+	pendingMutex.Unlock()
+
+	once.Do(func() { close(ch) })
+}
+
+func disconnectedSignal() <-chan struct{} {
+	pendingMutex.Lock()
+	defer pendingMutex.Unlock()
+	return disconnected
+}
+
+// request sends a command and waits for the response delivered under key.
+func request(ctx context.Context, key string, command any) (StreamDeckEvent, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultRequestTimeout)
+		defer cancel()
+	}
 
-// 	select {
-// 	case response := <-ch:
-// 		return response, nil
-// 	case <-time.After(timeout):
-// 		return nil, fmt.Errorf("timeout waiting for response")
-// 	}
-// }
+	done := disconnectedSignal()
+	req := addPendingRequest(key)
+	defer removePendingRequest(key, req)
+
+	if err := SendEventToStreamDeck(command); err != nil {
+		return nil, err
+	}
+
+	select {
+	case event := <-req.ch:
+		return event, nil
+	case <-done:
+		return nil, ErrDisconnected
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ErrTimeout
+		}
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,135 @@
+package streamdeck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stubWrites makes commands succeed without a connection.
+func stubWrites(t *testing.T) {
+	t.Helper()
+	original := writeFrame
+	writeFrame = func([]byte) error { return nil }
+	t.Cleanup(func() { writeFrame = original })
+}
+
+// waitPending waits until n callers are waiting on the key.
+func waitPending(t *testing.T, key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		pendingMutex.Lock()
+		waiting := len(pendingRequests[key])
+		pendingMutex.Unlock()
+		if waiting >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d callers are waiting on %s, want %d", waiting, key, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type requestResult struct {
+	event StreamDeckEvent
+	err   error
+}
+
+func startRequest(ctx context.Context, key string) chan requestResult {
+	result := make(chan requestResult, 1)
+	go func() {
+		event, err := request(ctx, key, GetSettingsCommand{Event: "getSettings", Context: key})
+		result <- requestResult{event, err}
+	}()
+	return result
+}
+
+func waitResult(t *testing.T, result chan requestResult) requestResult {
+	t.Helper()
+	select {
+	case r := <-result:
+		return r
+	case <-time.After(2 * time.Second):
+		t.Fatal("request didn't return")
+		return requestResult{}
+	}
+}
+
+func TestRequestSharedResponse(t *testing.T) {
+	stubWrites(t)
+	const key = "request-shared"
+
+	first := startRequest(context.Background(), key)
+	second := startRequest(context.Background(), key)
+	waitPending(t, key, 2)
+
+	response, err := ParseEvent([]byte(`{"event":"didReceiveSettings","action":"com.example.action","context":"request-shared","device":"device","payload":{"settings":{}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sendResponse(key, response)
+
+	for _, result := range []chan requestResult{first, second} {
+		r := waitResult(t, result)
+		if r.err != nil || r.event != response {
+			t.Errorf("got %v, %v, want the response", r.event, r.err)
+		}
+	}
+}
+
+func TestRequestCancelled(t *testing.T) {
+	stubWrites(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	result := startRequest(ctx, "request-cancelled")
+	waitPending(t, "request-cancelled", 1)
+	cancel()
+
+	if r := waitResult(t, result); !errors.Is(r.err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", r.err)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	stubWrites(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if r := waitResult(t, startRequest(ctx, "request-deadline")); !errors.Is(r.err, ErrTimeout) {
+		t.Errorf("got %v with a deadline, want ErrTimeout", r.err)
+	}
+
+	original := DefaultRequestTimeout
+	DefaultRequestTimeout = 10 * time.Millisecond
+	defer func() { DefaultRequestTimeout = original }()
+	if r := waitResult(t, startRequest(context.Background(), "request-default")); !errors.Is(r.err, ErrTimeout) {
+		t.Errorf("got %v with the default timeout, want ErrTimeout", r.err)
+	}
+}
+
+func TestRequestDisconnected(t *testing.T) {
+	stubWrites(t)
+	t.Cleanup(markConnected)
+
+	result := startRequest(context.Background(), "request-disconnected")
+	waitPending(t, "request-disconnected", 1)
+	markDisconnected()
+
+	if r := waitResult(t, result); !errors.Is(r.err, ErrDisconnected) {
+		t.Errorf("got %v, want ErrDisconnected", r.err)
+	}
+}
+
+func TestRequestAfterReconnect(t *testing.T) {
+	stubWrites(t)
+	markDisconnected()
+	markConnected()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if r := waitResult(t, startRequest(ctx, "request-reconnected")); !errors.Is(r.err, ErrTimeout) {
+		t.Errorf("got %v, want the request to wait on the new connection until ErrTimeout", r.err)
+	}
+}
@@ -0,0 +1,263 @@
+package streamdeck
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrSecretNotFound is returned by GetSecret for secrets that don't exist.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretsDir is where the local secret store keeps its key and data files. When empty,
+// a directory named after the plugin's manifest UUID in the user's config directory is used.
+var SecretsDir string
+
+const (
+	secretsKeyFile  = "secrets.key"
+	secretsDataFile = "secrets.enc"
+
+	secretsRequestKey = "didReceiveSecrets"
+
+	// Secrets are looked up while handling events, so the request gives up sooner than
+	// DefaultRequestTimeout, and after a failure the application isn't asked again for a while.
+	secretsRequestTimeout = 2 * time.Second
+	secretsRetryDelay     = 30 * time.Second
+)
+
+var (
+	secretsMutex     sync.Mutex
+	deckSecrets      map[string]string
+	deckSecretsRead  bool
+	deckSecretsFetch chan struct{} // Closed when the request in flight finishes.
+	deckSecretsRetry time.Time     // Time before which a failed request isn't sent again.
+)
+
+// Gets a secret. Secrets provided by the Stream Deck application take precedence over the
+// ones stored locally with SetSecret.
+//
+// Usage:
+//
+//	token, err := streamdeck.GetSecret("apiToken")
+//	if errors.Is(err, streamdeck.ErrSecretNotFound) {
+//		e.ShowAlert()
+//	}
+func GetSecret(name string) (string, error) {
+	if value, ok := readDeckSecrets()[name]; ok {
+		return value, nil
+	}
+
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+
+	secrets, err := readLocalSecrets()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[name]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+// Stores a secret in the local secret store, encrypted with a key that is generated once
+// per install. Unlike global settings, secrets are never visible to the property inspector.
+//
+// Usage:
+//
+//	err := streamdeck.SetSecret("apiToken", token)
+func SetSecret(name, value string) error {
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+
+	secrets, err := readLocalSecrets()
+	if err != nil {
+		return err
+	}
+	secrets[name] = value
+	return writeLocalSecrets(secrets)
+}
+
+// Removes a secret from the local secret store. Secrets provided by the Stream Deck
+// application can't be deleted by the plugin.
+//
+// Usage:
+//
+//	err := streamdeck.DeleteSecret("apiToken")
+func DeleteSecret(name string) error {
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+
+	secrets, err := readLocalSecrets()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[name]; !ok {
+		return nil
+	}
+	delete(secrets, name)
+	return writeLocalSecrets(secrets)
+}
+
+// readDeckSecrets fetches the secrets of the Stream Deck application, when the application
+// supports them. Lookups made while the request is in flight wait for it instead of sending
+// their own. Until a request succeeds they are asked for again, at most every
+// secretsRetryDelay, so a lookup made before the plugin is connected doesn't turn them off
+// for good.
+func readDeckSecrets() map[string]string {
+	secretsMutex.Lock()
+	if deckSecretsRead || !PluginConfig.Info.SupportsFeature(FeatureSecrets) || time.Now().Before(deckSecretsRetry) {
+		secrets := deckSecrets
+		secretsMutex.Unlock()
+		return secrets
+	}
+	if fetch := deckSecretsFetch; fetch != nil {
+		secretsMutex.Unlock()
+		<-fetch
+
+		secretsMutex.Lock()
+		defer secretsMutex.Unlock()
+		return deckSecrets
+	}
+	fetch := make(chan struct{})
+	deckSecretsFetch = fetch
+	secretsMutex.Unlock()
+
+	// The request can take until its timeout, so it's sent without holding secretsMutex.
+	response := GetSecretsCommand{
+		Event:   "getSecrets",
+		Context: PluginConfig.PluginUUID,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), secretsRequestTimeout)
+	event, err := request(ctx, secretsRequestKey, response)
+	cancel()
+
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+	deckSecretsFetch = nil
+	close(fetch)
+
+	if err != nil {
+		log.Printf("Error getting secrets from Stream Deck: %v", err)
+		deckSecretsRetry = time.Now().Add(secretsRetryDelay)
+		return nil
+	}
+	if secretsEvent, ok := event.(*DidReceiveSecretsEvent); ok {
+		deckSecrets = secretsEvent.Payload.Secrets
+	}
+	deckSecretsRead = true
+	return deckSecrets
+}
+
+func secretsDir() (string, error) {
+	if SecretsDir != "" {
+		return SecretsDir, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	// PluginConfig.PluginUUID identifies the registration, and changes every time the
+	// plugin is launched, so the store is keyed by the UUID from the manifest instead.
+	uuid := PluginConfig.Info.Plugin.UUID
+	if uuid == "" {
+		return "", fmt.Errorf("plugin UUID is not known yet")
+	}
+	return filepath.Join(configDir, uuid), nil
+}
+
+// secretsCipher loads the install's key, creating it on first use.
+func secretsCipher(dir string) (cipher.AEAD, error) {
+	path := filepath.Join(dir, secretsKeyFile)
+	key, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		key = make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, key, 0600); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func readLocalSecrets() (map[string]string, error) {
+	secrets := make(map[string]string)
+
+	dir, err := secretsDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, secretsDataFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return secrets, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	aead, err := secretsCipher(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("secrets file is corrupt")
+	}
+	nonce, sealed := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting secrets: %w", err)
+	}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+func writeLocalSecrets(secrets map[string]string) error {
+	dir, err := secretsDir()
+	if err != nil {
+		return err
+	}
+	aead, err := secretsCipher(dir)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so a crash never leaves a half-written store.
+	path := filepath.Join(dir, secretsDataFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, aead.Seal(nonce, nonce, plain, nil), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
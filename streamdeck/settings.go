@@ -0,0 +1,192 @@
+package streamdeck
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// SettingsVersionKey is the settings key the SDK stores the settings version under.
+const SettingsVersionKey = "settingsVersion"
+
+// SettingsMigration upgrades settings by one version.
+type SettingsMigration func(settings ActionSettings) (ActionSettings, error)
+
+// VersionedSettingsAction is implemented by actions that version their settings. Outdated
+// settings are migrated when they arrive with willAppear or didReceiveSettings, written
+// back with setSettings and only then handed to the handlers.
+//
+// Settings without a version keep the version last seen for the instance, as the property
+// inspector can save them without the version key; failing that they are version 0. Empty
+// settings belong to a new instance, which is simply stamped with the current version.
+// SetSettings stamps the current version onto settings saved without one.
+//
+// Usage:
+//
+//	func (a *WeatherAction) SettingsVersion() int {
+//		return 2
+//	}
+//
+//	func (a *WeatherAction) SettingsMigrations() []streamdeck.SettingsMigration {
+//		return []streamdeck.SettingsMigration{
+//			// 0 -> 1: "city" became "location"
+//			func(s streamdeck.ActionSettings) (streamdeck.ActionSettings, error) {
+//				s["location"] = s["city"]
+//				delete(s, "city")
+//				return s, nil
+//			},
+//			// 1 -> 2: temperatures are stored in Celsius
+//			migrateToCelsius,
+//		}
+//	}
+type VersionedSettingsAction interface {
+	Action
+	SettingsVersion() int
+	SettingsMigrations() []SettingsMigration // Index i upgrades version i to i+1.
+}
+
+// Returns the version the settings were saved with.
+func (s ActionSettings) Version() int {
+	version, _ := s[SettingsVersionKey].(float64)
+	return int(version)
+}
+
+// Decodes the settings into a struct, using its json tags.
+//
+// Usage:
+//
+//	var settings WeatherSettings
+//	err := event.Payload.Settings.Decode(&settings)
+func (s ActionSettings) Decode(v any) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// MigrateSettings upgrades settings to the given version.
+func MigrateSettings(settings ActionSettings, version int, migrations []SettingsMigration) (ActionSettings, error) {
+	if len(migrations) < version {
+		return nil, fmt.Errorf("%d migrations declared for settings version %d", len(migrations), version)
+	}
+	if settings == nil {
+		settings = make(ActionSettings)
+	}
+
+	from := settings.Version()
+	if from > version {
+		return nil, fmt.Errorf("settings version %d is newer than %d", from, version)
+	}
+	if len(settings) == 0 {
+		from = version
+	}
+
+	for v := from; v < version; v++ {
+		migrated, err := migrations[v](settings)
+		if err != nil {
+			return nil, fmt.Errorf("migrating settings from version %d: %w", v, err)
+		}
+		settings = migrated
+		if settings == nil {
+			settings = make(ActionSettings)
+		}
+	}
+	settings[SettingsVersionKey] = float64(version)
+	return settings, nil
+}
+
+// knownSettingsVersion returns the settings version last seen for the instance, reporting
+// false when it is not visible or its settings had no version.
+func knownSettingsVersion(context string) (int, bool) {
+	instanceMutex.RLock()
+	defer instanceMutex.RUnlock()
+
+	inst, ok := instanceRegistry[context]
+	if !ok {
+		return 0, false
+	}
+	if _, ok := inst.Settings[SettingsVersionKey]; !ok {
+		return 0, false
+	}
+	return inst.Settings.Version(), true
+}
+
+// stampSettingsVersion adds the current settings version to settings the plugin saves for
+// a VersionedSettingsAction, so they aren't taken for version 0 when they come back. The
+// caller's map is left alone.
+func stampSettingsVersion(actionUUID string, settings map[string]any) map[string]any {
+	versioned, ok := actionRegistry[actionUUID].(VersionedSettingsAction)
+	if !ok {
+		return settings
+	}
+	if _, ok := settings[SettingsVersionKey]; ok {
+		return settings
+	}
+	stamped := make(map[string]any, len(settings)+1)
+	for key, value := range settings {
+		stamped[key] = value
+	}
+	stamped[SettingsVersionKey] = float64(versioned.SettingsVersion())
+	return stamped
+}
+
+// prepareEventSettings migrates, fills in and validates the settings carried by
+// willAppear and didReceiveSettings in place, and saves them when they changed.
+func prepareEventSettings(event StreamDeckEvent) {
+	var (
+		actionEvent *ActionAssociatedEvent
+		settings    *ActionSettings
+	)
+	switch e := event.(type) {
+	case *WillAppearEvent:
+		actionEvent, settings = &e.ActionAssociatedEvent, &e.Payload.Settings
+	case *DidReceiveSettingsEvent:
+		actionEvent, settings = &e.ActionAssociatedEvent, &e.Payload.Settings
+	default:
+		return
+	}
+	if *settings == nil {
+		*settings = make(ActionSettings)
+	}
+
+	action := actionRegistry[actionEvent.Action]
+	changed := false
+
+	if versioned, ok := action.(VersionedSettingsAction); ok {
+		version := versioned.SettingsVersion()
+		if _, ok := (*settings)[SettingsVersionKey]; !ok && len(*settings) > 0 {
+			if known, ok := knownSettingsVersion(actionEvent.Context); ok {
+				(*settings)[SettingsVersionKey] = float64(known)
+				changed = true
+			}
+		}
+		if len(*settings) == 0 || (*settings).Version() != version {
+			migrated, err := MigrateSettings(*settings, version, versioned.SettingsMigrations())
+			if err != nil {
+				log.Printf("Error migrating settings of %s: %v", actionEvent.Context, err)
+				return
+			}
+			*settings = migrated
+			changed = true
+		}
+	}
+
+	if validated, ok := action.(ValidatedSettingsAction); ok {
+		if validateEventSettings(validated, actionEvent, *settings) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return
+	}
+	response := SetSettingsCommand{
+		Event:   "setSettings",
+		Context: actionEvent.Context,
+		Payload: *settings,
+	}
+	if err := SendEventToStreamDeck(response); err != nil {
+		log.Printf("Error saving settings of %s: %v", actionEvent.Context, err)
+	}
+}
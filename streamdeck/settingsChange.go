@@ -0,0 +1,86 @@
+package streamdeck
+
+import (
+	"reflect"
+	"sort"
+)
+
+// SettingsChange describes how an instance's settings changed. It is passed to
+// HandleSettingsChanged, which actions can implement to react to specific keys only:
+//
+//	func (a *WeatherAction) HandleSettingsChanged(event *streamdeck.DidReceiveSettingsEvent, change streamdeck.SettingsChange) {
+//		if change.Changed("endpoint", "apiKey") {
+//			a.restartPoller(event.Context)
+//		} else if change.Changed("color") {
+//			a.render(event.Context)
+//		}
+//	}
+type SettingsChange struct {
+	Old  ActionSettings
+	New  ActionSettings
+	Keys []string // Keys that were added, removed or changed, sorted.
+}
+
+// Reports whether any of the keys changed.
+func (c SettingsChange) Changed(keys ...string) bool {
+	for _, key := range keys {
+		i := sort.SearchStrings(c.Keys, key)
+		if i < len(c.Keys) && c.Keys[i] == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Decodes the old and new settings into structs.
+//
+// Usage:
+//
+//	var old, current WeatherSettings
+//	err := change.Decode(&old, &current)
+func (c SettingsChange) Decode(old, current any) error {
+	if err := c.Old.Decode(old); err != nil {
+		return err
+	}
+	return c.New.Decode(current)
+}
+
+// Compares two sets of settings.
+func DiffSettings(old, current ActionSettings) SettingsChange {
+	change := SettingsChange{Old: old, New: current}
+	for key, value := range current {
+		if previous, ok := old[key]; !ok || !reflect.DeepEqual(previous, value) {
+			change.Keys = append(change.Keys, key)
+		}
+	}
+	for key := range old {
+		if _, ok := current[key]; !ok {
+			change.Keys = append(change.Keys, key)
+		}
+	}
+	sort.Strings(change.Keys)
+	return change
+}
+
+// rememberPreviousSettings stores the settings an instance had before didReceiveSettings
+// replaces them in the registry.
+func rememberPreviousSettings(event StreamDeckEvent) {
+	if e, ok := event.(*DidReceiveSettingsEvent); ok {
+		if inst, ok := GetInstance(e.Context); ok {
+			e.previous = inst.Settings
+		}
+	}
+}
+
+func notifySettingsChanged(action Action, event *DidReceiveSettingsEvent) {
+	handler, ok := action.(interface {
+		HandleSettingsChanged(*DidReceiveSettingsEvent, SettingsChange)
+	})
+	if !ok {
+		return
+	}
+	change := DiffSettings(event.previous, event.Payload.Settings)
+	if len(change.Keys) > 0 {
+		handler.HandleSettingsChanged(event, change)
+	}
+}
@@ -0,0 +1,49 @@
+package streamdeck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     ActionSettings
+		current ActionSettings
+		want    []string
+	}{
+		{name: "unchanged", old: ActionSettings{"city": "Oslo"}, current: ActionSettings{"city": "Oslo"}},
+		{name: "both empty", old: nil, current: ActionSettings{}},
+		{name: "changed value", old: ActionSettings{"city": "Oslo"}, current: ActionSettings{"city": "Bergen"}, want: []string{"city"}},
+		{name: "added key", old: ActionSettings{}, current: ActionSettings{"units": "metric"}, want: []string{"units"}},
+		{name: "removed key", old: ActionSettings{"units": "metric"}, current: ActionSettings{}, want: []string{"units"}},
+		{name: "no previous settings", old: nil, current: ActionSettings{"city": "Oslo"}, want: []string{"city"}},
+		{
+			name:    "nested values compare deeply",
+			old:     ActionSettings{"tags": []any{"a", "b"}, "pos": map[string]any{"x": 1.0}},
+			current: ActionSettings{"tags": []any{"a", "b"}, "pos": map[string]any{"x": 2.0}},
+			want:    []string{"pos"},
+		},
+		{
+			name:    "keys are sorted",
+			old:     ActionSettings{"b": 1.0, "c": 1.0},
+			current: ActionSettings{"a": 1.0, "b": 2.0},
+			want:    []string{"a", "b", "c"},
+		},
+		{name: "type change", old: ActionSettings{"count": 1.0}, current: ActionSettings{"count": "1"}, want: []string{"count"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := DiffSettings(tt.old, tt.current)
+			if !reflect.DeepEqual(change.Keys, tt.want) {
+				t.Errorf("got keys %v, want %v", change.Keys, tt.want)
+			}
+			for _, key := range tt.want {
+				if !change.Changed(key) {
+					t.Errorf("Changed(%q) is false", key)
+				}
+			}
+		})
+	}
+}
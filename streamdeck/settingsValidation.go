@@ -0,0 +1,355 @@
+package streamdeck
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// InvalidSettingsReaction selects what the SDK does when settings fail validation. Values
+// can be combined.
+type InvalidSettingsReaction int
+
+const (
+	// Shows an alert on the key.
+	ReactShowAlert InvalidSettingsReaction = 1 << iota
+	// Replaces invalid fields with their default, or removes them when they have none.
+	ReactResetToDefaults
+	// Sends the field errors to the property inspector as {"settingsErrors": {...}}.
+	ReactNotifyPropertyInspector
+)
+
+// ValidatedSettingsAction is implemented by actions whose settings are described by a
+// struct. The struct's fields are matched to settings keys by their json tag, and the
+// sd tag declares defaults and rules:
+//
+//	type TimerSettings struct {
+//		Minutes int    `json:"minutes" sd:"default=5,required,min=1,max=60"`
+//		Sound   string `json:"sound" sd:"default=bell,enum=bell|chime|none"`
+//		Label   string `json:"label" sd:"max=12,regex=^[A-Za-z ]*$"`
+//	}
+//
+// For strings min and max limit the length. A regex must be the last rule, as it may
+// contain commas.
+//
+// Defaults are filled in and the settings validated whenever they arrive with willAppear
+// or didReceiveSettings, before the handlers see them.
+//
+// Usage:
+//
+//	func (a *TimerAction) SettingsSchema() any {
+//		return TimerSettings{}
+//	}
+//
+//	func (a *TimerAction) InvalidSettingsReaction() streamdeck.InvalidSettingsReaction {
+//		return streamdeck.ReactShowAlert | streamdeck.ReactNotifyPropertyInspector
+//	}
+type ValidatedSettingsAction interface {
+	Action
+	SettingsSchema() any
+}
+
+const defaultInvalidSettingsReaction = ReactShowAlert | ReactNotifyPropertyInspector
+
+// SettingsErrors maps settings keys to what is wrong with them.
+type SettingsErrors map[string]string
+
+func (e SettingsErrors) Error() string {
+	keys := make([]string, 0, len(e))
+	for key := range e {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	messages := make([]string, 0, len(keys))
+	for _, key := range keys {
+		messages = append(messages, key+": "+e[key])
+	}
+	return "invalid settings: " + strings.Join(messages, "; ")
+}
+
+type settingsRule struct {
+	key        string
+	kind       reflect.Kind
+	hasDefault bool
+	defaultVal any
+	required   bool
+	min, max   *float64
+	enum       []string
+	regex      *regexp.Regexp
+}
+
+// Returns the settings a new instance gets from the schema's defaults.
+func DefaultSettings(schema any) (ActionSettings, error) {
+	return ApplySettingsDefaults(make(ActionSettings), schema)
+}
+
+// Fills in the defaults declared by the schema for every missing key.
+func ApplySettingsDefaults(settings ActionSettings, schema any) (ActionSettings, error) {
+	rules, err := settingsRules(schema)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = make(ActionSettings)
+	}
+	fillDefaults(settings, rules)
+	return settings, nil
+}
+
+func fillDefaults(settings ActionSettings, rules []settingsRule) bool {
+	changed := false
+	for _, rule := range rules {
+		if value, ok := settings[rule.key]; (!ok || value == nil) && rule.hasDefault {
+			settings[rule.key] = rule.defaultVal
+			changed = true
+		}
+	}
+	return changed
+}
+
+// resetInvalid replaces every invalid value with its default, or removes it.
+func resetInvalid(settings ActionSettings, rules []settingsRule, errs SettingsErrors) {
+	for _, rule := range rules {
+		if _, invalid := errs[rule.key]; !invalid {
+			continue
+		}
+		if rule.hasDefault {
+			settings[rule.key] = rule.defaultVal
+		} else {
+			delete(settings, rule.key)
+		}
+	}
+}
+
+// validateEventSettings fills in defaults and reacts to invalid settings. It reports
+// whether the settings were changed.
+func validateEventSettings(action ValidatedSettingsAction, event *ActionAssociatedEvent, settings ActionSettings) bool {
+	rules, err := settingsRules(action.SettingsSchema())
+	if err != nil {
+		log.Printf("Error reading settings schema of %s: %v", event.Action, err)
+		return false
+	}
+	changed := fillDefaults(settings, rules)
+
+	errs := ValidateSettings(settings, action.SettingsSchema())
+	if errs == nil {
+		return changed
+	}
+	log.Printf("Settings of %s are invalid: %v", event.Context, errs)
+
+	reaction := defaultInvalidSettingsReaction
+	if reactingAction, ok := action.(interface {
+		InvalidSettingsReaction() InvalidSettingsReaction
+	}); ok {
+		reaction = reactingAction.InvalidSettingsReaction()
+	}
+
+	if reaction&ReactShowAlert != 0 {
+		if err := event.ShowAlert(); err != nil {
+			log.Printf("Error showing alert on %s: %v", event.Context, err)
+		}
+	}
+	if reaction&ReactNotifyPropertyInspector != 0 {
+		if err := event.SendToPropertyInspector(map[string]any{"settingsErrors": errs}); err != nil {
+			log.Printf("Error sending settings errors to %s: %v", event.Context, err)
+		}
+	}
+	if reaction&ReactResetToDefaults != 0 {
+		resetInvalid(settings, rules, errs)
+		changed = true
+	}
+	return changed
+}
+
+// Checks the settings against the rules declared by the schema.
+//
+// Usage:
+//
+//	if errs := streamdeck.ValidateSettings(settings, TimerSettings{}); errs != nil {
+//		log.Println(errs)
+//	}
+func ValidateSettings(settings ActionSettings, schema any) SettingsErrors {
+	rules, err := settingsRules(schema)
+	if err != nil {
+		return SettingsErrors{"": err.Error()}
+	}
+	errs := make(SettingsErrors)
+	for _, rule := range rules {
+		if message := rule.check(settings[rule.key]); message != "" {
+			errs[rule.key] = message
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (r settingsRule) check(value any) string {
+	if value == nil || value == "" {
+		if r.required {
+			return "is required"
+		}
+		return ""
+	}
+
+	switch r.kind {
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		length := float64(len([]rune(s)))
+		if r.min != nil && length < *r.min {
+			return fmt.Sprintf("must be at least %g characters", *r.min)
+		}
+		if r.max != nil && length > *r.max {
+			return fmt.Sprintf("must be at most %g characters", *r.max)
+		}
+		if r.regex != nil && !r.regex.MatchString(s) {
+			return fmt.Sprintf("must match %s", r.regex)
+		}
+		if len(r.enum) > 0 && !contains(r.enum, s) {
+			return "must be one of " + strings.Join(r.enum, ", ")
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			return "must be true or false"
+		}
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Interface:
+		// Only required is checked for composite values.
+	default:
+		n, ok := value.(float64)
+		if !ok {
+			return "must be a number"
+		}
+		if isIntKind(r.kind) && n != float64(int64(n)) {
+			return "must be a whole number"
+		}
+		if r.min != nil && n < *r.min {
+			return fmt.Sprintf("must be at least %g", *r.min)
+		}
+		if r.max != nil && n > *r.max {
+			return fmt.Sprintf("must be at most %g", *r.max)
+		}
+		if len(r.enum) > 0 && !contains(r.enum, strconv.FormatFloat(n, 'f', -1, 64)) {
+			return "must be one of " + strings.Join(r.enum, ", ")
+		}
+	}
+	return ""
+}
+
+func settingsRules(schema any) ([]settingsRule, error) {
+	t := reflect.TypeOf(schema)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("settings schema must be a struct, got %T", schema)
+	}
+
+	var rules []settingsRule
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			name, _, _ := strings.Cut(tag, ",")
+			if name == "-" {
+				continue
+			}
+			if name != "" {
+				key = name
+			}
+		}
+
+		rule := settingsRule{key: key, kind: field.Type.Kind()}
+		if err := rule.parse(field.Tag.Get("sd")); err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (r *settingsRule) parse(tag string) error {
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "regex=") {
+			part, tag = tag, ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+		}
+		name, value, _ := strings.Cut(part, "=")
+
+		switch name {
+		case "required":
+			r.required = true
+		case "default":
+			def, err := r.convert(value)
+			if err != nil {
+				return fmt.Errorf("default: %w", err)
+			}
+			r.hasDefault, r.defaultVal = true, def
+		case "min", "max":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			if name == "min" {
+				r.min = &n
+			} else {
+				r.max = &n
+			}
+		case "enum":
+			r.enum = strings.Split(value, "|")
+		case "regex":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return fmt.Errorf("regex: %w", err)
+			}
+			r.regex = re
+		default:
+			return fmt.Errorf("unknown rule %q", name)
+		}
+	}
+	return nil
+}
+
+// convert turns a default from the tag into the value it would decode to from JSON.
+func (r *settingsRule) convert(value string) (any, error) {
+	switch {
+	case r.kind == reflect.String:
+		return value, nil
+	case r.kind == reflect.Bool:
+		return strconv.ParseBool(value)
+	case isIntKind(r.kind), r.kind == reflect.Float32, r.kind == reflect.Float64:
+		return strconv.ParseFloat(value, 64)
+	}
+	return nil, fmt.Errorf("defaults are not supported for %s fields", r.kind)
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package streamdeck
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSettingsRuleParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    reflect.Kind
+		tag     string
+		check   func(t *testing.T, r settingsRule)
+		wantErr string
+	}{
+		{
+			name: "empty tag",
+			kind: reflect.String,
+			tag:  "",
+			check: func(t *testing.T, r settingsRule) {
+				if r.required || r.hasDefault || r.min != nil || r.max != nil || r.enum != nil || r.regex != nil {
+					t.Errorf("got rules from an empty tag: %+v", r)
+				}
+			},
+		},
+		{
+			name: "numeric default, required and bounds",
+			kind: reflect.Int,
+			tag:  "default=5,required,min=1,max=60",
+			check: func(t *testing.T, r settingsRule) {
+				if !r.required || !r.hasDefault || r.defaultVal != 5.0 {
+					t.Errorf("got required %t, default %v", r.required, r.defaultVal)
+				}
+				if r.min == nil || *r.min != 1 || r.max == nil || *r.max != 60 {
+					t.Errorf("got min %v, max %v", r.min, r.max)
+				}
+			},
+		},
+		{
+			name: "string default stays a string",
+			kind: reflect.String,
+			tag:  "default=42",
+			check: func(t *testing.T, r settingsRule) {
+				if r.defaultVal != "42" {
+					t.Errorf("got default %#v", r.defaultVal)
+				}
+			},
+		},
+		{
+			name: "enum",
+			kind: reflect.String,
+			tag:  "enum=bell|chime|none",
+			check: func(t *testing.T, r settingsRule) {
+				if !reflect.DeepEqual(r.enum, []string{"bell", "chime", "none"}) {
+					t.Errorf("got enum %v", r.enum)
+				}
+			},
+		},
+		{
+			name: "regex last may contain commas",
+			kind: reflect.String,
+			tag:  "max=12,regex=^[a-z]{1,3}(,[a-z]{1,3})*$",
+			check: func(t *testing.T, r settingsRule) {
+				if r.regex == nil || r.regex.String() != "^[a-z]{1,3}(,[a-z]{1,3})*$" {
+					t.Errorf("got regex %v", r.regex)
+				}
+				if r.max == nil || *r.max != 12 {
+					t.Errorf("got max %v", r.max)
+				}
+			},
+		},
+		{
+			name: "rules after a regex belong to it",
+			kind: reflect.String,
+			tag:  "regex=^a$,required",
+			check: func(t *testing.T, r settingsRule) {
+				if r.required {
+					t.Error("required after regex was parsed as a rule")
+				}
+				if r.regex == nil || r.regex.String() != "^a$,required" {
+					t.Errorf("got regex %v", r.regex)
+				}
+			},
+		},
+		{name: "unknown rule", kind: reflect.String, tag: "maxlen=3", wantErr: `unknown rule "maxlen"`},
+		{name: "bad number", kind: reflect.Int, tag: "min=one", wantErr: "min:"},
+		{name: "bad bool default", kind: reflect.Bool, tag: "default=maybe", wantErr: "default:"},
+		{name: "bad regex", kind: reflect.String, tag: "regex=[", wantErr: "regex:"},
+		{name: "default on a slice", kind: reflect.Slice, tag: "default=a", wantErr: "not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := settingsRule{kind: tt.kind}
+			err := r.parse(tt.tag)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, r)
+		})
+	}
+}
+
+type timerSettings struct {
+	Minutes int      `json:"minutes" sd:"default=5,required,min=1,max=60"`
+	Sound   string   `json:"sound" sd:"default=bell,enum=bell|chime|none"`
+	Label   string   `json:"label" sd:"max=12,regex=^[A-Za-z ]*$"`
+	Loud    bool     `json:"loud"`
+	Tags    []string `json:"tags" sd:"required"`
+	Ignored string   `json:"-" sd:"required"`
+}
+
+func TestValidateSettings(t *testing.T) {
+	valid := func() ActionSettings {
+		return ActionSettings{"minutes": 5.0, "sound": "bell", "label": "Tea", "loud": true, "tags": []any{"a"}}
+	}
+
+	tests := []struct {
+		name   string
+		change func(ActionSettings)
+		want   []string // Keys with errors.
+	}{
+		{name: "valid", change: func(ActionSettings) {}},
+		{name: "missing required", change: func(s ActionSettings) { delete(s, "minutes") }, want: []string{"minutes"}},
+		{name: "empty required composite", change: func(s ActionSettings) { s["tags"] = nil }, want: []string{"tags"}},
+		{name: "below min", change: func(s ActionSettings) { s["minutes"] = 0.0 }, want: []string{"minutes"}},
+		{name: "above max", change: func(s ActionSettings) { s["minutes"] = 61.0 }, want: []string{"minutes"}},
+		{name: "fractional int", change: func(s ActionSettings) { s["minutes"] = 1.5 }, want: []string{"minutes"}},
+		{name: "number as string", change: func(s ActionSettings) { s["minutes"] = "5" }, want: []string{"minutes"}},
+		{name: "not in enum", change: func(s ActionSettings) { s["sound"] = "horn" }, want: []string{"sound"}},
+		{name: "string too long", change: func(s ActionSettings) { s["label"] = "A very long label" }, want: []string{"label"}},
+		{name: "regex mismatch", change: func(s ActionSettings) { s["label"] = "Tea 2" }, want: []string{"label"}},
+		{name: "wrong bool type", change: func(s ActionSettings) { s["loud"] = "yes" }, want: []string{"loud"}},
+		{
+			name:   "several errors",
+			change: func(s ActionSettings) { s["minutes"] = 100.0; s["sound"] = 1.0 },
+			want:   []string{"minutes", "sound"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := valid()
+			tt.change(settings)
+
+			errs := ValidateSettings(settings, timerSettings{})
+			var got []string
+			for key := range errs {
+				got = append(got, key)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got errors %v, want errors for %v", errs, tt.want)
+			}
+			for _, key := range tt.want {
+				if _, ok := errs[key]; !ok {
+					t.Errorf("got errors %v, want one for %s", errs, key)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultSettings(t *testing.T) {
+	settings, err := DefaultSettings(&timerSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ActionSettings{"minutes": 5.0, "sound": "bell"}
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("got %v, want %v", settings, want)
+	}
+
+	if _, err := DefaultSettings("not a struct"); err == nil {
+		t.Error("got no error for a schema that isn't a struct")
+	}
+}
@@ -0,0 +1,195 @@
+package streamdeck
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+// StatefulAction tracks the state of every instance of a multi-state action. Embed it in
+// an action and the SDK keeps it up to date from willAppear, keyUp and
+// titleParametersDidChange, honouring UserDesiredState inside multi-actions.
+//
+// Usage:
+//
+//	type MuteAction struct {
+//		streamdeck.ActionConfig
+//		streamdeck.StatefulAction
+//	}
+//
+//	var Mute = &MuteAction{
+//		ActionConfig:   streamdeck.ActionConfig{UUID: "com.example.mute"},
+//		StatefulAction: streamdeck.StatefulAction{Titles: []string{"Live", "Muted"}, SettingsKey: "state"},
+//	}
+//
+//	func (a *MuteAction) HandleDialDown(event *streamdeck.DialDownEvent) {
+//		a.Toggle(event.Context)
+//	}
+type StatefulAction struct {
+	States      int      // Number of states declared in the manifest, at most MaxStates. Defaults to 2.
+	Titles      []string // Optional title for each state, set whenever the state changes.
+	Images      []string // Optional image for each state, set whenever the state changes.
+	SettingsKey string   // Settings key the state is persisted under. Empty disables persistence.
+
+	mu     sync.Mutex
+	states map[string]uint8
+}
+
+type statefulProvider interface {
+	stateful() *StatefulAction
+}
+
+func (s *StatefulAction) stateful() *StatefulAction {
+	return s
+}
+
+func (s *StatefulAction) stateCount() int {
+	if s.States <= 0 {
+		return 2
+	}
+	return s.States
+}
+
+// Returns the current state of the instance with the given context.
+func (s *StatefulAction) State(context string) (uint8, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[context]
+	return state, ok
+}
+
+// Switches the instance to its next state, wrapping around after the last one.
+//
+// Usage:
+//
+//	state, err := a.Toggle(event.Context)
+func (s *StatefulAction) Toggle(context string) (uint8, error) {
+	current, _ := s.State(context)
+	next := uint8((int(current) + 1) % s.stateCount())
+	return next, s.Set(context, next)
+}
+
+// Switches the instance to the given state.
+//
+// Usage:
+//
+//	a.Set(event.Context, 1)
+func (s *StatefulAction) Set(context string, state uint8) error {
+	if s.States > MaxStates {
+		return invalidCommand("the action declares %d states, the manifest allows at most %d", s.States, MaxStates)
+	}
+	if int(state) >= s.stateCount() {
+		return fmt.Errorf("state %d is out of range, the action has %d states", state, s.stateCount())
+	}
+	inst, ok := GetInstance(context)
+	if !ok {
+		return contextGoneError(context)
+	}
+	if err := inst.SetState(state); err != nil {
+		return err
+	}
+	s.record(context, state)
+	return s.apply(&inst, state)
+}
+
+func (s *StatefulAction) record(context string, state uint8) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.states == nil {
+		s.states = make(map[string]uint8)
+	}
+	s.states[context] = state
+}
+
+func (s *StatefulAction) forget(context string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, context)
+}
+
+// apply sets the title and image of the state and persists it.
+func (s *StatefulAction) apply(inst *Instance, state uint8) error {
+	if int(state) < len(s.Titles) {
+		if err := inst.SetTitle(s.Titles[state]); err != nil {
+			return err
+		}
+	}
+	if int(state) < len(s.Images) {
+		if err := inst.SetImage(s.Images[state]); err != nil {
+			return err
+		}
+	}
+	if s.SettingsKey != "" {
+		settings := inst.Settings
+		if settings == nil {
+			settings = make(ActionSettings)
+		}
+		if stored, ok := settings[s.SettingsKey].(float64); !ok || uint8(stored) != state {
+			// Stored as a float64, which is what it decodes to when the settings come back.
+			settings[s.SettingsKey] = float64(state)
+			return inst.SetSettings(settings)
+		}
+	}
+	return nil
+}
+
+// trackState updates the state of stateful actions from the events reporting it.
+func trackState(event StreamDeckEvent) {
+	actionEvent, ok := event.(interface {
+		GetAction() (string, bool)
+		GetContext() string
+	})
+	if !ok {
+		return
+	}
+	actionUUID, _ := actionEvent.GetAction()
+	provider, ok := actionRegistry[actionUUID].(statefulProvider)
+	if !ok {
+		return
+	}
+	s := provider.stateful()
+	context := actionEvent.GetContext()
+
+	switch e := event.(type) {
+	case *WillAppearEvent:
+		state := uint8(e.Payload.State)
+		if stored, ok := e.Payload.Settings[s.SettingsKey].(float64); ok && s.SettingsKey != "" && uint8(stored) != state {
+			if err := s.Set(context, uint8(stored)); err != nil {
+				log.Printf("Error restoring state of %s: %v", context, err)
+			}
+			return
+		}
+		s.record(context, state)
+	case *KeyUpEvent:
+		if e.Payload.IsInMultiAction {
+			// Multi-actions ask for a specific state rather than toggling.
+			if err := s.Set(context, uint8(e.Payload.UserDesiredState)); err != nil {
+				log.Printf("Error setting state of %s: %v", context, err)
+			}
+			return
+		}
+		state := uint8(e.Payload.State)
+		s.record(context, state)
+		if inst, ok := GetInstance(context); ok {
+			if err := s.apply(&inst, state); err != nil {
+				log.Printf("Error applying state of %s: %v", context, err)
+			}
+		}
+	case *TitleParametersDidChangeEvent:
+		s.record(context, uint8(e.Payload.State))
+	case *WillDisappearEvent:
+		s.forget(context)
+	}
+}
+
+// resetStatefulActions forgets the states of every registered StatefulAction.
+func resetStatefulActions() {
+	for _, action := range actionRegistry {
+		if provider, ok := action.(statefulProvider); ok {
+			s := provider.stateful()
+			s.mu.Lock()
+			s.states = nil
+			s.mu.Unlock()
+		}
+	}
+}
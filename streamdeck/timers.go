@@ -0,0 +1,208 @@
+package streamdeck
+
+import (
+	"sync"
+	"time"
+)
+
+// Timer is a callback scheduled on an action instance with Every or After. Callbacks run
+// on the instance's event queue, so they never overlap with its handlers. A Timer is
+// stopped automatically when the instance disappears or the plugin shuts down.
+type Timer struct {
+	context  string
+	interval time.Duration
+	repeat   bool
+	fn       func()
+
+	mu         sync.Mutex
+	timer      clockTimer
+	deadline   time.Time
+	generation int
+	pending    bool
+	stopped    bool
+}
+
+// MinTimerInterval is the shortest delay Every and After accept. Shorter ones, including
+// zero and negative durations, are raised to it so a timer can't spin.
+const MinTimerInterval = 10 * time.Millisecond
+
+var (
+	instanceTimers = make(map[string]map[*Timer]struct{})
+	timerMutex     sync.Mutex
+)
+
+// Calls fn every d for as long as the instance is visible. A tick is skipped while the
+// previous call is still queued or running. d is raised to MinTimerInterval when shorter.
+//
+// Usage:
+//
+//	func (a *ClockAction) HandleWillAppear(event *streamdeck.WillAppearEvent) {
+//		inst, _ := streamdeck.GetInstance(event.Context)
+//		inst.Every(time.Second, func() {
+//			inst.SetTitle(time.Now().Format("15:04:05"))
+//		})
+//	}
+func (i *Instance) Every(d time.Duration, fn func()) *Timer {
+	return startTimer(i.Context, d, true, fn)
+}
+
+// Calls fn once after d, unless the instance disappears first. d is raised to
+// MinTimerInterval when shorter.
+//
+// Usage:
+//
+//	inst.After(3*time.Second, func() {
+//		inst.SetTitle("")
+//	})
+func (i *Instance) After(d time.Duration, fn func()) *Timer {
+	return startTimer(i.Context, d, false, fn)
+}
+
+// Stops the timer. Calling Stop more than once has no effect.
+func (t *Timer) Stop() {
+	t.mu.Lock()
+	t.stop()
+	t.mu.Unlock()
+	t.forget()
+}
+
+func startTimer(context string, d time.Duration, repeat bool, fn func()) *Timer {
+	d = max(d, MinTimerInterval)
+	t := &Timer{context: context, interval: d, repeat: repeat, fn: fn}
+
+	queueMutex.Lock()
+	_, visible := instanceQueues[context]
+	queueMutex.Unlock()
+	if !visible {
+		t.stopped = true
+		return t
+	}
+
+	timerMutex.Lock()
+	if instanceTimers[context] == nil {
+		instanceTimers[context] = make(map[*Timer]struct{})
+	}
+	instanceTimers[context][t] = struct{}{}
+	timerMutex.Unlock()
+
+	t.mu.Lock()
+	t.arm(d)
+	t.mu.Unlock()
+	return t
+}
+
+// arm must be called with t.mu held.
+func (t *Timer) arm(d time.Duration) {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	t.generation++
+	generation := t.generation
+	t.deadline = now().Add(d)
+	t.timer = afterFunc(d, func() { t.fire(generation) })
+}
+
+// stop must be called with t.mu held.
+func (t *Timer) stop() {
+	t.stopped = true
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+func (t *Timer) fire(generation int) {
+	t.mu.Lock()
+	if t.stopped || generation != t.generation {
+		t.mu.Unlock()
+		return
+	}
+	if t.repeat {
+		t.arm(t.interval)
+	} else {
+		t.stopped = true
+	}
+	skip := t.pending
+	t.pending = true
+	t.mu.Unlock()
+
+	if !t.repeat {
+		t.forget()
+	}
+	if skip {
+		return
+	}
+
+	queued := enqueue(t.context, func() {
+		t.mu.Lock()
+		t.pending = false
+		stopped := t.stopped && t.repeat
+		t.mu.Unlock()
+		if !stopped {
+			t.fn()
+		}
+	})
+	if !queued {
+		t.Stop()
+	}
+}
+
+func (t *Timer) forget() {
+	timerMutex.Lock()
+	defer timerMutex.Unlock()
+
+	delete(instanceTimers[t.context], t)
+	if len(instanceTimers[t.context]) == 0 {
+		delete(instanceTimers, t.context)
+	}
+}
+
+func stopInstanceTimers(context string) {
+	timerMutex.Lock()
+	timers := instanceTimers[context]
+	delete(instanceTimers, context)
+	timerMutex.Unlock()
+
+	for t := range timers {
+		t.mu.Lock()
+		t.stop()
+		t.mu.Unlock()
+	}
+}
+
+func stopAllTimers() {
+	timerMutex.Lock()
+	contexts := make([]string, 0, len(instanceTimers))
+	for context := range instanceTimers {
+		contexts = append(contexts, context)
+	}
+	timerMutex.Unlock()
+
+	for _, context := range contexts {
+		stopInstanceTimers(context)
+	}
+}
+
+// rescheduleTimers restarts every running timer after the system wakes up, so repeating
+// timers don't fire a burst of stale ticks and one-shot timers keep their remaining delay.
+func rescheduleTimers() {
+	timerMutex.Lock()
+	var timers []*Timer
+	for _, set := range instanceTimers {
+		for t := range set {
+			timers = append(timers, t)
+		}
+	}
+	timerMutex.Unlock()
+
+	for _, t := range timers {
+		t.mu.Lock()
+		if !t.stopped {
+			if t.repeat {
+				t.arm(t.interval)
+			} else {
+				t.arm(max(t.deadline.Sub(now()), 0))
+			}
+		}
+		t.mu.Unlock()
+	}
+}
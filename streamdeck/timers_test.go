@@ -0,0 +1,115 @@
+package streamdeck
+
+import (
+	"testing"
+	"time"
+)
+
+// showTimerInstance opens the queue of an instance for the test, as willAppear does.
+func showTimerInstance(t *testing.T) *Instance {
+	t.Helper()
+	inst := &Instance{}
+	inst.Context = "timer-" + t.Name()
+	openInstanceQueue(inst.Context)
+	t.Cleanup(func() { closeInstanceQueue(inst.Context) })
+	return inst
+}
+
+func waitTicks(t *testing.T, ticks chan int, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-ticks:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for tick %d of %d", i+1, n)
+		}
+	}
+}
+
+// flushQueue waits until the tasks queued on the instance so far have run.
+func flushQueue(t *testing.T, context string) {
+	t.Helper()
+	done := make(chan struct{})
+	if !enqueue(context, func() { close(done) }) {
+		t.Fatal("instance queue is closed")
+	}
+	<-done
+}
+
+func TestTimerAfter(t *testing.T) {
+	inst := showTimerInstance(t)
+	ticks := make(chan int, 8)
+	inst.After(10*time.Millisecond, func() { ticks <- 1 })
+
+	waitTicks(t, ticks, 1)
+	time.Sleep(50 * time.Millisecond)
+	if len(ticks) != 0 {
+		t.Errorf("After fired %d more times", len(ticks))
+	}
+}
+
+func TestTimerEvery(t *testing.T) {
+	inst := showTimerInstance(t)
+	ticks := make(chan int, 64)
+	timer := inst.Every(10*time.Millisecond, func() { ticks <- 1 })
+
+	waitTicks(t, ticks, 3)
+	timer.Stop()
+	flushQueue(t, inst.Context)
+	for len(ticks) > 0 {
+		<-ticks
+	}
+	time.Sleep(50 * time.Millisecond)
+	if len(ticks) != 0 {
+		t.Errorf("Every fired %d times after Stop", len(ticks))
+	}
+}
+
+func TestTimerStopsWhenInstanceDisappears(t *testing.T) {
+	inst := showTimerInstance(t)
+	ticks := make(chan int, 64)
+	inst.Every(10*time.Millisecond, func() { ticks <- 1 })
+	inst.After(30*time.Millisecond, func() { ticks <- 2 })
+
+	waitTicks(t, ticks, 1)
+	flushQueue(t, inst.Context)
+	closeInstanceQueue(inst.Context)
+	// A tick queued before the close may still be running.
+	time.Sleep(20 * time.Millisecond)
+	for len(ticks) > 0 {
+		<-ticks
+	}
+	time.Sleep(50 * time.Millisecond)
+	if len(ticks) != 0 {
+		t.Errorf("timers fired %d times after the instance disappeared", len(ticks))
+	}
+	timerMutex.Lock()
+	remaining := len(instanceTimers[inst.Context])
+	timerMutex.Unlock()
+	if remaining != 0 {
+		t.Errorf("%d timers are still registered", remaining)
+	}
+}
+
+func TestTimerOnHiddenInstance(t *testing.T) {
+	inst := &Instance{}
+	inst.Context = "timer-hidden"
+	ticks := make(chan int, 1)
+	inst.After(10*time.Millisecond, func() { ticks <- 1 })
+
+	time.Sleep(50 * time.Millisecond)
+	if len(ticks) != 0 {
+		t.Error("a timer fired for an instance that isn't visible")
+	}
+}
+
+func TestTimerMinimumInterval(t *testing.T) {
+	inst := showTimerInstance(t)
+	for _, d := range []time.Duration{-time.Second, 0, time.Millisecond, MinTimerInterval} {
+		timer := inst.Every(d, func() {})
+		timer.Stop()
+		if timer.interval != MinTimerInterval {
+			t.Errorf("Every(%v) runs every %v, want %v", d, timer.interval, MinTimerInterval)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package streamdeck
+
+import "sync"
+
+// LayoutAction is implemented by actions whose manifest declares a custom layout, so
+// touch taps can be routed to its items before SetLayout is called.
+//
+// Usage:
+//
+//	func (a *MixerAction) Layout() *streamdeck.Layout {
+//		return MixerLayout
+//	}
+type LayoutAction interface {
+	Action
+	Layout() *Layout
+}
+
+var (
+	contextLayouts = make(map[string]*Layout)
+	layoutMutex    sync.RWMutex
+)
+
+// Sets a custom layout on the action instance and remembers it, so touch taps are routed
+// to its items with HandleTouchItem.
+//
+// Usage:
+//
+//	e.SetLayout(MixerLayout)
+func (r *ActionRef) SetLayout(layout *Layout) error {
+	if err := layout.Validate(); err != nil {
+		return err
+	}
+	if err := r.SetFeedbackLayout(layout.Path); err != nil {
+		return err
+	}
+	layoutMutex.Lock()
+	contextLayouts[r.Context] = layout
+	layoutMutex.Unlock()
+	return nil
+}
+
+func forgetLayout(context string) {
+	layoutMutex.Lock()
+	delete(contextLayouts, context)
+	layoutMutex.Unlock()
+}
+
+func resetLayouts() {
+	layoutMutex.Lock()
+	contextLayouts = make(map[string]*Layout)
+	layoutMutex.Unlock()
+}
+
+func layoutFor(action Action, context string) *Layout {
+	layoutMutex.RLock()
+	layout, ok := contextLayouts[context]
+	layoutMutex.RUnlock()
+	if ok {
+		return layout
+	}
+	if layoutAction, ok := action.(LayoutAction); ok {
+		return layoutAction.Layout()
+	}
+	return nil
+}
+
+// HitTest returns the key of the topmost enabled item at the given position on the
+// touch strip.
+func (l *Layout) HitTest(pos TapPosition) (string, bool) {
+	var (
+		hit   *ItemBase
+		found bool
+	)
+	for _, item := range l.Items {
+		b := item.base()
+		if b.Enabled != nil && !*b.Enabled {
+			continue
+		}
+		if !b.Rect.contains(pos[0], pos[1]) {
+			continue
+		}
+		// Later items are drawn on top of earlier ones with the same z-order.
+		if !found || b.ZOrder >= hit.ZOrder {
+			hit = b
+			found = true
+		}
+	}
+	if !found {
+		return "", false
+	}
+	return hit.Key, true
+}
+
+// routeTouchTap dispatches a touch tap to the layout item under it. Holds go to
+// HandleTouchItemHold when the action implements it, and to HandleTouchItem otherwise.
+func routeTouchTap(action Action, event *TouchTapEvent) {
+	layout := layoutFor(action, event.Context)
+	if layout == nil {
+		return
+	}
+	key, ok := layout.HitTest(event.Payload.TapPos)
+	if !ok {
+		return
+	}
+
+	if event.Payload.Hold {
+		if handler, ok := action.(interface {
+			HandleTouchItemHold(string, *TouchTapEvent)
+		}); ok {
+			handler.HandleTouchItemHold(key, event)
+			return
+		}
+	}
+	if handler, ok := action.(interface {
+		HandleTouchItem(string, *TouchTapEvent)
+	}); ok {
+		handler.HandleTouchItem(key, event)
+	}
+}
@@ -0,0 +1,42 @@
+package streamdeck
+
+import "testing"
+
+func TestLayoutHitTest(t *testing.T) {
+	disabled := false
+	layout := &Layout{
+		ID: "mixer",
+		Items: []LayoutItem{
+			&PixmapItem{ItemBase: ItemBase{Key: "background", Rect: Rect{0, 0, 200, 100}}},
+			&TextItem{ItemBase: ItemBase{Key: "title", Rect: Rect{16, 10, 168, 24}, ZOrder: 1}},
+			&TextItem{ItemBase: ItemBase{Key: "overlay", Rect: Rect{16, 10, 84, 24}, ZOrder: 1}},
+			&BarItem{ItemBase: ItemBase{Key: "indicator", Rect: Rect{16, 60, 168, 20}, ZOrder: 2}},
+			&PixmapItem{ItemBase: ItemBase{Key: "hidden", Rect: Rect{0, 50, 200, 50}, ZOrder: 5, Enabled: &disabled}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		pos     TapPosition
+		wantKey string
+		wantHit bool
+	}{
+		{name: "only the background", pos: TapPosition{5, 5}, wantKey: "background", wantHit: true},
+		{name: "higher z-order wins", pos: TapPosition{150, 20}, wantKey: "title", wantHit: true},
+		{name: "later item wins on equal z-order", pos: TapPosition{50, 20}, wantKey: "overlay", wantHit: true},
+		{name: "disabled items are skipped", pos: TapPosition{100, 70}, wantKey: "indicator", wantHit: true},
+		{name: "disabled item over the background", pos: TapPosition{5, 90}, wantKey: "background", wantHit: true},
+		{name: "left and top edges are inside", pos: TapPosition{16, 60}, wantKey: "indicator", wantHit: true},
+		{name: "right and bottom edges are outside", pos: TapPosition{184, 80}, wantKey: "background", wantHit: true},
+		{name: "outside the canvas", pos: TapPosition{250, 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, hit := layout.HitTest(tt.pos)
+			if key != tt.wantKey || hit != tt.wantHit {
+				t.Errorf("got %q, %t, want %q, %t", key, hit, tt.wantKey, tt.wantHit)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package streamdeck
+
+import "log"
+
+// resumeAfterWake brings the plugin back up to date after the system slept: timers are
+// rescheduled, global settings fetched again, and every visible instance whose action
+// implements HandleResume(Instance) gets a chance to refresh, on its own queue.
+func resumeAfterWake() {
+	rescheduleTimers()
+
+	if err := requestGlobalSettings(); err != nil {
+		log.Printf("Error requesting global settings after wake: %v", err)
+	}
+
+	instanceMutex.RLock()
+	instances := make([]Instance, 0, len(instanceRegistry))
+	for _, inst := range instanceRegistry {
+		instances = append(instances, inst.snapshot())
+	}
+	instanceMutex.RUnlock()
+
+	for _, inst := range instances {
+		handler, ok := actionRegistry[inst.Action].(interface {
+			HandleResume(Instance)
+		})
+		if !ok {
+			continue
+		}
+		if !enqueue(inst.Context, func() { handler.HandleResume(inst) }) {
+			go handler.HandleResume(inst)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package streamdeck
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"sync"
 
 	"github.com/gorilla/websocket"
 )
@@ -12,21 +14,37 @@ type PluginConfigType struct {
 	Port          string
 	PluginUUID    string
 	RegisterEvent string
-	Info          StreamDeckInfo
+	Info          Info
 }
 
 var PluginConfig PluginConfigType
 
 var WsClient *websocket.Conn
 
+// writeMutex serialises writes, as the WebSocket connection supports only one writer at a time.
+var writeMutex sync.Mutex
+
+// writeFrame delivers an encoded command. It is swapped out while replaying a session.
+var writeFrame = func(data []byte) error {
+	writeMutex.Lock()
+	defer writeMutex.Unlock()
+
+	if WsClient == nil {
+		return fmt.Errorf("WebSocket client is not initialised")
+	}
+	return WsClient.WriteMessage(websocket.TextMessage, data)
+}
+
 // func OpenWebsocketAndRegisterPlugin() {
 
 // }
 
 func SendEventToStreamDeck(response interface{}) error {
-	if WsClient == nil {
-		return fmt.Errorf("WebSocket client is not initialised")
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
 	}
-	log.Println("SD <-", response)
-	return WsClient.WriteJSON(response)
+	log.Println("SD <-", string(data))
+	recordFrame(FrameOut, data)
+	return writeFrame(data)
 }